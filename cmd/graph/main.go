@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/tkhamez/eve-route-go/internal/db"
+	"github.com/tkhamez/eve-route-go/internal/graph"
+)
+
+const usage = `usage:
+  graph diff [-json] old.json new.json
+  graph validate [-json] graph.json`
+
+// main содержит служебные команды для работы с файлами графа карты.
+func main() {
+	if len(os.Args) < 2 {
+		log.Fatal(usage)
+	}
+	switch os.Args[1] {
+	case "diff":
+		os.Exit(runDiff(os.Args[2:]))
+	case "validate":
+		os.Exit(runValidate(os.Args[2:]))
+	default:
+		log.Fatalf("unknown command %q\n%s", os.Args[1], usage)
+	}
+}
+
+// runDiff сравнивает два файла графа. Код возврата как у diff(1):
+// 0 — различий нет, 1 — есть различия, 2 — ошибка.
+func runDiff(args []string) int {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	asJSON := flags.Bool("json", false, "print machine-readable JSON")
+	_ = flags.Parse(args)
+	if flags.NArg() != 2 {
+		log.Print(usage)
+		return 2
+	}
+	ctx := context.Background()
+	old, err := db.LoadGraph(ctx, flags.Arg(0))
+	if err != nil {
+		log.Printf("graph diff: %v", err)
+		return 2
+	}
+	next, err := db.LoadGraph(ctx, flags.Arg(1))
+	if err != nil {
+		log.Printf("graph diff: %v", err)
+		return 2
+	}
+	d := graph.Compare(old, next)
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(d)
+	} else {
+		err = d.WriteText(os.Stdout)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "graph diff: %v\n", err)
+		return 2
+	}
+	if d.Empty() {
+		return 0
+	}
+	return 1
+}
+
+// runValidate проверяет целостность файла графа. Код возврата 1 означает,
+// что найдены ошибки; 2 — файл не удалось прочитать.
+func runValidate(args []string) int {
+	flags := flag.NewFlagSet("validate", flag.ExitOnError)
+	asJSON := flags.Bool("json", false, "print machine-readable JSON")
+	_ = flags.Parse(args)
+	if flags.NArg() != 1 {
+		log.Print(usage)
+		return 2
+	}
+	g, err := db.LoadGraph(context.Background(), flags.Arg(0))
+	if err != nil {
+		log.Printf("graph validate: %v", err)
+		return 2
+	}
+	r := graph.Validate(g)
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(r)
+	} else {
+		for _, f := range r.Findings {
+			if _, err = fmt.Println(f); err != nil {
+				break
+			}
+		}
+		if err == nil {
+			_, err = fmt.Printf("%d errors, %d warnings\n", r.Count(graph.SeverityError), r.Count(graph.SeverityWarning))
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "graph validate: %v\n", err)
+		return 2
+	}
+	if r.HasErrors() {
+		return 1
+	}
+	return 0
+}
@@ -3,23 +3,31 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"flag"
 	"log"
 	"os"
 
 	"github.com/tkhamez/eve-route-go/internal/esi"
+	"github.com/tkhamez/eve-route-go/internal/graph"
 	"github.com/tkhamez/eve-route-go/internal/importer"
 )
 
 func main() {
+	strict := flag.Bool("strict", false, "do not print a graph with integrity errors")
+	flag.Parse()
 	ctx := context.Background()
 	client := esi.NewClient(nil, "eve-route-importer")
-	graph, err := importer.BuildGraph(ctx, client)
+	g, err := importer.BuildGraph(ctx, client)
 	if err != nil {
 		log.Fatalf("import failed: %v", err)
 	}
+	// проблемы пишутся в stderr через log, stdout остаётся чистым JSON
+	if _, err := graph.Check(g, *strict, "import-data"); err != nil {
+		log.Fatalf("import failed: %v", err)
+	}
 	enc := json.NewEncoder(os.Stdout)
 	enc.SetIndent("", "  ")
-	if err := enc.Encode(graph); err != nil {
+	if err := enc.Encode(g); err != nil {
 		log.Fatalf("encode graph: %v", err)
 	}
 }
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/tkhamez/eve-route-go/internal/db"
+	"github.com/tkhamez/eve-route-go/internal/graph"
+	"github.com/tkhamez/eve-route-go/internal/importer"
+)
+
+// main строит граф карты из локальной выгрузки SDE без обращения к ESI.
+// Использование: import-sde [-strict] <sde.zip|каталог> [graph.json]
+func main() {
+	strict := flag.Bool("strict", false, "do not save a graph with integrity errors")
+	flag.Parse()
+	if flag.NArg() < 1 {
+		log.Fatalf("usage: %s [-strict] <sde.zip|dir> [graph.json]", os.Args[0])
+	}
+	ctx := context.Background()
+	log.Printf("import-sde: reading %s", flag.Arg(0))
+	g, err := importer.BuildGraphFromSDE(flag.Arg(0))
+	if err != nil {
+		log.Fatalf("import-sde: build graph: %v", err)
+	}
+	if _, err := graph.Check(g, *strict, "import-sde"); err != nil {
+		log.Fatalf("import-sde: %v", err)
+	}
+	path := "graph.json"
+	if flag.NArg() > 1 {
+		path = flag.Arg(1)
+	}
+	if err := db.StoreGraph(ctx, path, g); err != nil {
+		log.Fatalf("import-sde: store graph: %v", err)
+	}
+	log.Printf("import-sde: graph saved to %s", path)
+}
@@ -2,16 +2,20 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log"
-	"os"
 
 	"github.com/tkhamez/eve-route-go/internal/db"
 	"github.com/tkhamez/eve-route-go/internal/esi"
+	"github.com/tkhamez/eve-route-go/internal/graph"
 	"github.com/tkhamez/eve-route-go/internal/importer"
 )
 
 // main запускает процесс импорта данных ESI и сохраняет граф.
+// Использование: import [-strict] [graph.json]
 func main() {
+	strict := flag.Bool("strict", false, "do not save a graph with integrity errors")
+	flag.Parse()
 	ctx := context.Background()
 	client := esi.NewClient(nil, "eve-route-importer")
 	log.Println("import: building graph from ESI")
@@ -19,9 +23,12 @@ func main() {
 	if err != nil {
 		log.Fatalf("import: build graph: %v", err)
 	}
+	if _, err := graph.Check(g, *strict, "import"); err != nil {
+		log.Fatalf("import: %v", err)
+	}
 	path := "graph.json"
-	if len(os.Args) > 1 {
-		path = os.Args[1]
+	if flag.NArg() > 0 {
+		path = flag.Arg(0)
 	}
 	if err := db.StoreGraph(ctx, path, g); err != nil {
 		log.Fatalf("import: store graph: %v", err)
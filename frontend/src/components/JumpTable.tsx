@@ -8,44 +8,18 @@ import {
   Typography,
 } from "@material-ui/core";
 import { useTranslation } from "react-i18next";
-
-const FUEL_PER_LY = 1000; // количество изотопов на один световой год
-const LY_IN_METERS = 9.4607e15;
-
-interface System {
-  id: number;
-  name: string;
-  regionName?: string;
-  x: number;
-  y: number;
-  z: number;
-}
+import { ResponseCapital } from "../response";
 
 /**
- * JumpTable отображает список прыжков с расстоянием и расходом топлива.
+ * JumpTable отображает прыжки маршрута с расстоянием и расходом топлива,
+ * рассчитанными сервером.
  */
-export default function JumpTable({ systems }: { systems: System[] }) {
+export default function JumpTable({ plan }: { plan: ResponseCapital | null }) {
   const { t } = useTranslation();
-  if (systems.length < 2) {
+  if (!plan || plan.legs.length === 0) {
     return null;
   }
 
-  const rows = systems.slice(1).map((s, i) => {
-    const prev = systems[i];
-    const dx = prev.x - s.x;
-    const dy = prev.y - s.y;
-    const dz = prev.z - s.z;
-    const distance = Math.sqrt(dx * dx + dy * dy + dz * dz) / LY_IN_METERS;
-    return {
-      system: s.name,
-      region: s.regionName || "",
-      distance,
-      fuel: distance * FUEL_PER_LY,
-    };
-  });
-
-  const totalFuel = rows.reduce((sum, r) => sum + r.fuel, 0);
-
   return (
     <>
       <Table size="small">
@@ -53,24 +27,34 @@ export default function JumpTable({ systems }: { systems: System[] }) {
           <TableRow>
             <TableCell>{t("capital.system")}</TableCell>
             <TableCell>{t("capital.region")}</TableCell>
+            <TableCell>{t("capital.security")}</TableCell>
             <TableCell>{t("capital.distance")}</TableCell>
-            <TableCell>{t("capital.fuel")}</TableCell>
+            <TableCell>{t("capital.cumulative")}</TableCell>
+            {plan.fuel && <TableCell>{t("capital.fuel")}</TableCell>}
           </TableRow>
         </TableHead>
         <TableBody>
-          {rows.map((r, i) => (
+          {plan.legs.map((leg, i) => (
             <TableRow key={i}>
-              <TableCell>{r.system}</TableCell>
-              <TableCell>{r.region}</TableCell>
-              <TableCell>{r.distance.toFixed(2)}</TableCell>
-              <TableCell>{Math.round(r.fuel)}</TableCell>
+              <TableCell>{leg.to.name}</TableCell>
+              <TableCell>{leg.to.region}</TableCell>
+              <TableCell>{leg.to.security.toFixed(1)}</TableCell>
+              <TableCell>{leg.distance.toFixed(2)}</TableCell>
+              <TableCell>{leg.cumulative.toFixed(2)}</TableCell>
+              {plan.fuel && <TableCell>{plan.fuel.legs[i].fuel}</TableCell>}
             </TableRow>
           ))}
         </TableBody>
       </Table>
-      <Typography variant="caption">
-        {t("capital.total-fuel")}: {Math.round(totalFuel)}
+      <Typography variant="caption" component="div">
+        {t("capital.total-distance")}: {plan.distance.toFixed(2)}
       </Typography>
+      {plan.fuel && (
+        <Typography variant="caption" component="div">
+          {t("capital.total-fuel")}: {plan.fuel.total}
+          {plan.fuel.isotope && ` ${plan.fuel.isotope}`}
+        </Typography>
+      )}
     </>
   );
 }
@@ -42,10 +42,8 @@ export default function LeafletMap({ systems }: Props) {
       return;
     }
     console.info("Drawing route with", systems.length, "systems");
-    const scale = 1e16; // перевод метров в условные градусы
-    const coords = systems.map(
-      (s) => [s.y / scale, s.x / scale] as [number, number],
-    );
+    // координаты приходят в световых годах и используются как условные градусы
+    const coords = systems.map((s) => [s.y, s.x] as [number, number]);
     const polyline = L.polyline(coords, { color: "red" }).addTo(map);
     map.fitBounds(polyline.getBounds());
   }, [systems]);
@@ -0,0 +1,95 @@
+import React, { useContext, useEffect, useState } from "react";
+import { Box, TextField } from "@material-ui/core";
+import { useTranslation } from "react-i18next";
+import axios from "axios";
+import { GlobalDataContext } from "../GlobalDataContext";
+import { CapitalShip, ResponseCapitalShips } from "../response";
+import { ShipOptions } from "../types";
+
+// races определяют изотоп, который расходует корабль.
+const races = ["amarr", "caldari", "gallente", "minmatar"];
+
+// skillLevels — уровни навыков Jump Drive Calibration и Jump Fuel Conservation.
+const skillLevels = [5, 4, 3, 2, 1, 0];
+
+/**
+ * ShipOptionsInput позволяет выбрать корабль, его расу и уровни навыков,
+ * по которым сервер считает дальность прыжка и расход топлива.
+ * Классы кораблей загружаются с сервера (/api/capital/ships).
+ */
+export default function ShipOptionsInput({
+  value,
+  onChange,
+}: {
+  value: ShipOptions;
+  onChange: (value: ShipOptions) => void;
+}) {
+  const { t } = useTranslation();
+  const globalData = useContext(GlobalDataContext);
+  const [ships, setShips] = useState<Array<CapitalShip>>([]);
+
+  useEffect(() => {
+    axios
+      .get<ResponseCapitalShips>(`${globalData.domain}/api/capital/ships`)
+      .then((r) => {
+        setShips(r.data.ships);
+      })
+      .catch(() => {});
+  }, [globalData.domain]);
+
+  return (
+    <Box display="flex" justifyContent="center" flexWrap="wrap">
+      <TextField
+        select
+        id="capital-ship"
+        label={t("capital.ship")}
+        value={value.ship}
+        onChange={(e) => onChange({ ...value, ship: e.target.value })}
+        SelectProps={{ native: true }}
+        margin="dense"
+      >
+        {ships.map((s) => (
+          <option key={s.class} value={s.class}>
+            {s.name}
+          </option>
+        ))}
+      </TextField>
+      <TextField
+        select
+        id="capital-race"
+        label={t("capital.race")}
+        value={value.race}
+        onChange={(e) => onChange({ ...value, race: e.target.value })}
+        SelectProps={{ native: true }}
+        margin="dense"
+      >
+        <option value="">{t("capital.race-any")}</option>
+        {races.map((r) => (
+          <option key={r} value={r}>
+            {r.charAt(0).toUpperCase() + r.slice(1)}
+          </option>
+        ))}
+      </TextField>
+      {(["jdc", "jfc"] as const).map((skill) => (
+        <TextField
+          select
+          key={skill}
+          id={`capital-${skill}`}
+          label={t(`capital.${skill}`)}
+          value={value[skill]}
+          onChange={(e) =>
+            onChange({ ...value, [skill]: Number(e.target.value) })
+          }
+          SelectProps={{ native: true }}
+          margin="dense"
+        >
+          {skillLevels.map((l) => (
+            <option key={l} value={l}>
+              {l}
+            </option>
+          ))}
+        </TextField>
+      ))}
+    </Box>
+  );
+}
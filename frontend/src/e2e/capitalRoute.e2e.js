@@ -10,12 +10,31 @@ async function run() {
   await page.goto(url + "#Capital");
   await page.type("#start-system", "Start");
   await page.type("#end-system", "End");
+  await page.waitForSelector('#capital-ship option[value="carrier"]');
+  await page.select("#capital-ship", "carrier");
+  await page.select("#capital-race", "amarr");
+  await page.select("#capital-jfc", "4");
   await page.click("#find-route");
   await page.waitForSelector(".leaflet-interactive");
   const count = await page.$$eval(".leaflet-interactive", (els) => els.length);
   if (count === 0) {
     throw new Error("Polyline not rendered");
   }
+  const query = server.capitalQuery;
+  for (const [key, value] of [
+    ["ship", "carrier"],
+    ["race", "amarr"],
+    ["jdc", "5"],
+    ["jfc", "4"],
+  ]) {
+    if (!query || query.get(key) !== value) {
+      throw new Error(`Expected ${key}=${value} in capital request: ${query}`);
+    }
+  }
+  // сервер вернул расход топлива — он должен появиться под таблицей прыжков
+  await page.waitForFunction(() =>
+    document.body.innerText.includes("3000 Oxygen Isotopes"),
+  );
   await browser.close();
   server.close();
 }
@@ -25,18 +44,50 @@ function startServer() {
   return new Promise((resolve) => {
     const server = http.createServer((req, res) => {
       const url = req.url ? req.url.split("?")[0] : "/";
-      if (url.startsWith("/api/capital")) {
+      if (url === "/api/capital/ships") {
         res.writeHead(200, { "Content-Type": "application/json" });
         res.end(
           JSON.stringify({
-            route: [
-              { id: 1, name: "Start", x: 0, y: 0, z: 0 },
-              { id: 2, name: "End", x: 1e16, y: 0, z: 0 },
+            ships: [
+              { class: "carrier", name: "Carrier", baseRange: 3.5 },
+              { class: "jf", name: "Jump Freighter", baseRange: 5 },
             ],
           }),
         );
         return;
       }
+      if (url.startsWith("/api/capital")) {
+        server.capitalQuery = new URL(req.url, "http://localhost").searchParams;
+        res.writeHead(200, { "Content-Type": "application/json" });
+        const start = {
+          id: 1,
+          name: "Start",
+          security: 0.3,
+          regionId: 1,
+          region: "R",
+          x: 0,
+          y: 0,
+          z: 0,
+        };
+        const end = { ...start, id: 2, name: "End", x: 1 };
+        res.end(
+          JSON.stringify({
+            mode: "jumps",
+            range: 5,
+            systems: [start, end],
+            legs: [{ from: start, to: end, distance: 1, cumulative: 1 }],
+            jumps: 1,
+            distance: 1,
+            fuel: {
+              isotope: "Oxygen Isotopes",
+              perLy: 3000,
+              legs: [{ from: "Start", to: "End", distance: 1, fuel: 3000 }],
+              total: 3000,
+            },
+          }),
+        );
+        return;
+      }
       if (url.startsWith("/api/auth/user")) {
         res.writeHead(200, { "Content-Type": "application/json" });
         res.end(
@@ -5,32 +5,49 @@ import axios from "axios";
 import SystemInput from "../components/SystemInput";
 import LeafletMap from "../components/LeafletMap";
 import JumpTable from "../components/JumpTable";
+import ShipOptionsInput from "../components/ShipOptionsInput";
 import { GlobalDataContext } from "../GlobalDataContext";
-import { CapitalSystem, ResponseCapital } from "../response";
+import { ResponseCapital } from "../response";
+import { ShipOptions } from "../types";
 
 /**
  * Страница Capital Jump Planner.
  * Позволяет рассчитать маршрут прыжков капитального корабля
- * и отобразить его на карте.
+ * и отобразить его на карте. Корабль, раса и навыки отправляются на сервер,
+ * который считает по ним дальность прыжка и расход топлива.
  */
 export default function Capital() {
   const { t } = useTranslation();
   const globalData = useContext(GlobalDataContext);
   const [start, setStart] = useState("");
   const [end, setEnd] = useState("");
+  const [ship, setShip] = useState<ShipOptions>({
+    ship: "jf",
+    race: "",
+    jdc: 5,
+    jfc: 5,
+  });
 
-  const [route, setRoute] = useState<CapitalSystem[]>([]);
+  const [plan, setPlan] = useState<ResponseCapital | null>(null);
   const [message, setMessage] = useState("");
 
   const findRoute = () => {
     setMessage("");
+    const params = new URLSearchParams({
+      start,
+      end,
+      ship: ship.ship,
+      jdc: String(ship.jdc),
+      jfc: String(ship.jfc),
+    });
+    if (ship.race) {
+      params.set("race", ship.race);
+    }
     axios
-      .get<ResponseCapital>(
-        `${globalData.domain}/api/capital?start=${start}&end=${end}`,
-      )
+      .get<ResponseCapital>(`${globalData.domain}/api/capital?${params}`)
       .then((r) => {
-        console.info("Received capital route", r.data.route);
-        setRoute(r.data.route);
+        console.info("Received capital route", r.data.legs);
+        setPlan(r.data);
       })
       .catch(() => {
         setMessage(t("capital.no-route"));
@@ -69,6 +86,10 @@ export default function Capital() {
         </Box>
       </Grid>
 
+      <Grid item xs={12}>
+        <ShipOptionsInput value={ship} onChange={setShip} />
+      </Grid>
+
       <Grid item xs={12}>
         <Box display="flex" justifyContent="center">
           <Button
@@ -85,10 +106,10 @@ export default function Capital() {
 
       <Grid item sm={4} xs={12}>
         {message && <Typography>{message}</Typography>}
-        {!message && <JumpTable systems={route} />}
+        {!message && <JumpTable plan={plan} />}
       </Grid>
       <Grid item sm={8} xs={12}>
-        <LeafletMap systems={route} />
+        <LeafletMap systems={plan ? plan.systems : []} />
       </Grid>
     </Grid>
   );
@@ -27,6 +27,10 @@ jest.mock(
     ),
 );
 
+jest.mock("../components/ShipOptionsInput", () => () => (
+  <div data-testid="ship-options" />
+));
+
 test("Capital snapshot", () => {
   const { container } = render(
     <ThemeProvider theme={theme}>
@@ -37,19 +41,30 @@ test("Capital snapshot", () => {
 });
 
 test("renders route with region and fuel", async () => {
+  const start = {
+    id: 1,
+    name: "Start",
+    security: 0.3,
+    regionId: 1,
+    region: "Alpha",
+    x: 0,
+    y: 0,
+    z: 0,
+  };
+  const end = { ...start, id: 2, name: "End", region: "Beta", x: 1 };
   (axios.get as jest.Mock).mockResolvedValueOnce({
     data: {
-      route: [
-        { id: 1, name: "Start", regionName: "Alpha", x: 0, y: 0, z: 0 },
-        {
-          id: 2,
-          name: "End",
-          regionName: "Beta",
-          x: 9.4607e15,
-          y: 0,
-          z: 0,
-        },
-      ],
+      mode: "jumps",
+      range: 5,
+      systems: [start, end],
+      legs: [{ from: start, to: end, distance: 1, cumulative: 1 }],
+      jumps: 1,
+      distance: 1,
+      fuel: {
+        perLy: 1000,
+        legs: [{ from: "Start", to: "End", distance: 1, fuel: 1000 }],
+        total: 1000,
+      },
     },
   });
 
@@ -71,5 +86,15 @@ test("renders route with region and fuel", async () => {
   await waitFor(() => screen.getByText("End"));
   expect(screen.getByText("Beta")).toBeInTheDocument();
   expect(screen.getByText("1000")).toBeInTheDocument();
+  expect(screen.getByText("0.3")).toBeInTheDocument();
   expect(screen.getByTestId("map")).toHaveTextContent("2");
+  // без выбора пользователя отправляется профиль JF с максимальными навыками
+  const url = new URL(
+    (axios.get as jest.Mock).mock.calls[0][0],
+    "http://localhost",
+  );
+  expect(url.searchParams.get("ship")).toBe("jf");
+  expect(url.searchParams.get("jdc")).toBe("5");
+  expect(url.searchParams.get("jfc")).toBe("5");
+  expect(url.searchParams.has("race")).toBe(false);
 });
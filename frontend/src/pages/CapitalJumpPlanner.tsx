@@ -2,10 +2,7 @@ import React, { useState } from "react";
 import { Box, Button, Grid, Typography } from "@material-ui/core";
 import axios from "axios";
 import SystemInput from "../components/SystemInput";
-
-interface ApiResponse {
-  route: Array<{ id: number; name: string }>;
-}
+import { ResponseCapital } from "../response";
 
 /**
  * Страница планировщика прыжков капитальных кораблей.
@@ -25,9 +22,9 @@ export default function CapitalJumpPlanner() {
     }
     console.log("Requesting capital route", start, end);
     axios
-      .get<ApiResponse>(`/api/capital?start=${start}&end=${end}`)
+      .get<ResponseCapital>(`/api/capital?start=${start}&end=${end}`)
       .then((r) => {
-        setNames(r.data.route.map((s) => s.name));
+        setNames(r.data.systems.map((s) => s.name));
       })
       .catch((err) => {
         console.error("Failed to fetch capital route", err);
@@ -65,19 +65,57 @@ export type ResponseRouteLocation = {
   solarSystemName: string | null;
 };
 
-// CapitalSystem описывает систему с координатами для капитального маршрута.
+// CapitalSystem описывает систему маршрута; координаты в световых годах.
 export type CapitalSystem = {
   id: number;
   name: string;
-  regionName?: string;
+  security: number;
+  regionId: number;
+  region: string;
   x: number;
   y: number;
   z: number;
 };
 
+// CapitalLeg описывает один прыжок; cumulative — расстояние от начала маршрута.
+export type CapitalLeg = {
+  from: CapitalSystem;
+  to: CapitalSystem;
+  distance: number;
+  cumulative: number;
+};
+
+// CapitalFuel описывает расход изотопов по прыжкам и на весь маршрут.
+export type CapitalFuel = {
+  isotope?: string;
+  perLy: number;
+  legs: Array<{ from: string; to: string; distance: number; fuel: number }>;
+  total: number;
+};
+
 // ResponseCapital описывает ответ API /api/capital.
 export type ResponseCapital = {
-  route: Array<CapitalSystem>;
+  mode: string;
+  ship?: { class: string; name: string };
+  jdc?: number;
+  range: number;
+  systems: Array<CapitalSystem>;
+  legs: Array<CapitalLeg>;
+  jumps: number;
+  distance: number;
+  fuel?: CapitalFuel;
+};
+
+// CapitalShip описывает класс корабля из /api/capital/ships; дальность в световых годах.
+export type CapitalShip = {
+  class: string;
+  name: string;
+  baseRange: number;
+};
+
+// ResponseCapitalShips описывает ответ API /api/capital/ships.
+export type ResponseCapitalShips = {
+  ships: Array<CapitalShip>;
 };
 
 export type Ansiblex = {
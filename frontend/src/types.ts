@@ -15,3 +15,11 @@ export type System = {
   security: number,
   position: { x: number, y: number }
 }
+
+// ShipOptions — корабль, раса и навыки для расчёта дальности прыжка и топлива.
+export type ShipOptions = {
+  ship: string,
+  race: string,
+  jdc: number,
+  jfc: number,
+}
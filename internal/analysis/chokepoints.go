@@ -0,0 +1,312 @@
+package analysis
+
+import (
+	"context"
+	"log"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	routepkg "github.com/tkhamez/eve-route-go/internal/route"
+)
+
+// Chokepoint — система, через которую проходит значительная часть маршрутов
+// или без которой сеть распадается.
+type Chokepoint struct {
+	System SystemRef `json:"system"`
+	// Articulation — шарнир: без системы сеть распадается на части.
+	Articulation bool `json:"articulation"`
+	// Isolated — сколько систем теряют связь с основной частью сети без этой системы.
+	Isolated int `json:"isolated"`
+	// Betweenness — количество пар систем, кратчайшие пути между которыми
+	// проходят через систему (с долями при нескольких кратчайших путях).
+	Betweenness float64 `json:"betweenness"`
+}
+
+// Bridge — соединение, без которого сеть распадается на части.
+type Bridge struct {
+	From SystemRef             `json:"from"`
+	To   SystemRef             `json:"to"`
+	Type routepkg.WaypointType `json:"type"`
+	// Isolated — размер меньшей из частей, на которые распадается сеть.
+	Isolated int `json:"isolated"`
+}
+
+// Report — результат анализа сети.
+// Узкие места отсортированы по Isolated, затем по Betweenness;
+// мосты — по Isolated. Системы, не влияющие на связность и не лежащие
+// ни на одном кратчайшем пути, в отчёт не входят.
+type Report struct {
+	Network     Mode         `json:"network"`
+	Chokepoints []Chokepoint `json:"chokepoints"`
+	Bridges     []Bridge     `json:"bridges"`
+}
+
+// RegionReport — узкие места одного региона.
+type RegionReport struct {
+	Region      string       `json:"region"`
+	Chokepoints []Chokepoint `json:"chokepoints"`
+	Bridges     []Bridge     `json:"bridges"`
+}
+
+// Chokepoints находит шарниры, мосты и центральность по посредничеству для сети n.
+// Расчёт центральности занимает O(V·E) и выполняется параллельно;
+// при отмене ctx возвращается ошибка контекста.
+func Chokepoints(ctx context.Context, n *Network) (Report, error) {
+	log.Printf("analysis: chokepoints for %d systems, %d connections (%s)", n.Len(), len(n.edges), n.mode)
+	isolated, articulation, bridges := n.cuts()
+	betweenness, err := n.betweenness(ctx)
+	if err != nil {
+		return Report{}, err
+	}
+	r := Report{Network: n.mode, Chokepoints: []Chokepoint{}, Bridges: []Bridge{}}
+	for v := range n.systems {
+		if !articulation[v] && betweenness[v] == 0 {
+			continue
+		}
+		r.Chokepoints = append(r.Chokepoints, Chokepoint{
+			System:       n.ref(v),
+			Articulation: articulation[v],
+			Isolated:     isolated[v],
+			Betweenness:  betweenness[v],
+		})
+	}
+	sort.Slice(r.Chokepoints, func(i, j int) bool {
+		a, b := r.Chokepoints[i], r.Chokepoints[j]
+		if a.Isolated != b.Isolated {
+			return a.Isolated > b.Isolated
+		}
+		if a.Betweenness != b.Betweenness {
+			return a.Betweenness > b.Betweenness
+		}
+		return a.System.ID < b.System.ID
+	})
+	for _, b := range bridges {
+		e := n.edges[b.edge]
+		r.Bridges = append(r.Bridges, Bridge{From: n.ref(e.A), To: n.ref(e.B), Type: e.Type, Isolated: b.isolated})
+	}
+	sort.Slice(r.Bridges, func(i, j int) bool {
+		a, b := r.Bridges[i], r.Bridges[j]
+		if a.Isolated != b.Isolated {
+			return a.Isolated > b.Isolated
+		}
+		if a.From.ID != b.From.ID {
+			return a.From.ID < b.From.ID
+		}
+		return a.To.ID < b.To.ID
+	})
+	return r, nil
+}
+
+// ByRegion группирует отчёт по регионам, сохраняя порядок ранжирования и оставляя
+// не более limit записей каждого вида (0 — без ограничения). Мост попадает в регионы
+// обеих систем. Если region не пуст, возвращается только регион с этим названием.
+// Регионы отсортированы по названию.
+func (r Report) ByRegion(region string, limit int) []RegionReport {
+	byName := map[string]*RegionReport{}
+	get := func(name string) *RegionReport {
+		if region != "" && !strings.EqualFold(region, name) {
+			return nil
+		}
+		rr, ok := byName[name]
+		if !ok {
+			rr = &RegionReport{Region: name, Chokepoints: []Chokepoint{}, Bridges: []Bridge{}}
+			byName[name] = rr
+		}
+		return rr
+	}
+	for _, c := range r.Chokepoints {
+		if rr := get(c.System.Region); rr != nil && (limit <= 0 || len(rr.Chokepoints) < limit) {
+			rr.Chokepoints = append(rr.Chokepoints, c)
+		}
+	}
+	for _, b := range r.Bridges {
+		names := []string{b.From.Region}
+		if b.To.Region != b.From.Region {
+			names = append(names, b.To.Region)
+		}
+		for _, name := range names {
+			if rr := get(name); rr != nil && (limit <= 0 || len(rr.Bridges) < limit) {
+				rr.Bridges = append(rr.Bridges, b)
+			}
+		}
+	}
+	res := make([]RegionReport, 0, len(byName))
+	for _, rr := range byName {
+		res = append(res, *rr)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Region < res[j].Region })
+	return res
+}
+
+type bridgeCut struct {
+	edge     int
+	isolated int
+}
+
+// cuts находит шарниры и мосты алгоритмом Тарьяна (итеративный обход в глубину).
+// Для каждой системы возвращает число систем, отрезанных от основной части
+// её компоненты связности, если систему убрать.
+func (n *Network) cuts() (isolated []int, articulation []bool, bridges []bridgeCut) {
+	count := n.Len()
+	disc := make([]int, count) // 0 — система ещё не посещена
+	low := make([]int, count)
+	size := make([]int, count)
+	parentEdge := make([]int, count)
+	pieces := make([][]int, count) // размеры частей, отделяемых системой
+	isolated = make([]int, count)
+	articulation = make([]bool, count)
+
+	type frame struct {
+		v, next int
+	}
+	time := 0
+	for root := 0; root < count; root++ {
+		if disc[root] != 0 {
+			continue
+		}
+		var component []int
+		var pending []bridgeCut // размер меньшей части известен после обхода компоненты
+		time++
+		disc[root], low[root], size[root], parentEdge[root] = time, time, 1, -1
+		component = append(component, root)
+		stack := []frame{{v: root}}
+		for len(stack) > 0 {
+			top := &stack[len(stack)-1]
+			v := top.v
+			if top.next < len(n.adj[v]) {
+				e := n.adj[v][top.next]
+				top.next++
+				if e.id == parentEdge[v] {
+					continue
+				}
+				if disc[e.to] == 0 {
+					time++
+					disc[e.to], low[e.to], size[e.to], parentEdge[e.to] = time, time, 1, e.id
+					component = append(component, e.to)
+					stack = append(stack, frame{v: e.to})
+				} else if disc[e.to] < low[v] {
+					low[v] = disc[e.to]
+				}
+				continue
+			}
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				break
+			}
+			p := stack[len(stack)-1].v
+			size[p] += size[v]
+			if low[v] < low[p] {
+				low[p] = low[v]
+			}
+			if low[v] >= disc[p] {
+				pieces[p] = append(pieces[p], size[v])
+			}
+			if low[v] > disc[p] {
+				pending = append(pending, bridgeCut{edge: parentEdge[v], isolated: size[v]})
+			}
+		}
+
+		total := size[root]
+		for _, v := range component {
+			parts := pieces[v]
+			if v != root {
+				rest := total - 1
+				for _, p := range parts {
+					rest -= p
+				}
+				if rest > 0 {
+					parts = append(parts, rest)
+				}
+			}
+			if len(parts) < 2 {
+				continue
+			}
+			articulation[v] = true
+			sum, largest := 0, 0
+			for _, p := range parts {
+				sum += p
+				largest = max(largest, p)
+			}
+			isolated[v] = sum - largest
+		}
+		for _, b := range pending {
+			b.isolated = min(b.isolated, total-b.isolated)
+			bridges = append(bridges, b)
+		}
+	}
+	return isolated, articulation, bridges
+}
+
+// betweenness считает центральность по посредничеству алгоритмом Брандеса.
+// Источники распределяются между горутинами, у каждой свои буферы.
+func (n *Network) betweenness(ctx context.Context) ([]float64, error) {
+	count := n.Len()
+	nbrs := n.neighbours()
+	workers := min(runtime.GOMAXPROCS(0), max(count, 1))
+	results := make([][]float64, workers)
+	sources := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			cb := make([]float64, count)
+			dist := make([]int32, count)
+			sigma := make([]float64, count)
+			delta := make([]float64, count)
+			order := make([]int32, 0, count)
+			for s := range sources {
+				for i := range dist {
+					dist[i], sigma[i], delta[i] = -1, 0, 0
+				}
+				order = order[:0]
+				dist[s], sigma[s] = 0, 1
+				order = append(order, int32(s))
+				for head := 0; head < len(order); head++ {
+					v := order[head]
+					for _, u := range nbrs[v] {
+						if dist[u] < 0 {
+							dist[u] = dist[v] + 1
+							order = append(order, u)
+						}
+						if dist[u] == dist[v]+1 {
+							sigma[u] += sigma[v]
+						}
+					}
+				}
+				for i := len(order) - 1; i > 0; i-- {
+					u := order[i]
+					for _, v := range nbrs[u] {
+						if dist[v] == dist[u]-1 {
+							delta[v] += sigma[v] / sigma[u] * (1 + delta[u])
+						}
+					}
+					cb[u] += delta[u]
+				}
+			}
+			results[w] = cb
+		}(w)
+	}
+	var err error
+	for s := 0; s < count; s++ {
+		if err = ctx.Err(); err != nil {
+			break
+		}
+		sources <- s
+	}
+	close(sources)
+	wg.Wait()
+	if err != nil {
+		return nil, err
+	}
+	total := make([]float64, count)
+	for _, cb := range results {
+		for v, x := range cb {
+			// каждая пара учтена из обоих концов
+			total[v] += x / 2
+		}
+	}
+	return total, nil
+}
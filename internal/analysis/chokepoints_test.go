@@ -0,0 +1,286 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	dbstore "github.com/tkhamez/eve-route-go/internal/dbstore"
+	"github.com/tkhamez/eve-route-go/internal/graph"
+	routepkg "github.com/tkhamez/eve-route-go/internal/route"
+)
+
+// lineGraph возвращает цепочку систем A-B-C-D-E в двух регионах.
+func lineGraph() graph.Graph {
+	return graph.Graph{
+		Systems: []graph.System{
+			{ID: 1, Name: "A", RegionID: 1},
+			{ID: 2, Name: "B", RegionID: 1},
+			{ID: 3, Name: "C", RegionID: 1},
+			{ID: 4, Name: "D", RegionID: 2},
+			{ID: 5, Name: "E", RegionID: 2},
+		},
+		Connections: [][2]int{{1, 2}, {2, 3}, {3, 4}, {4, 5}},
+		Regions:     map[int]string{1: "West", 2: "East"},
+	}
+}
+
+// ansiblexPair возвращает пару Ansiblex между системами a и b.
+func ansiblexPair(id int64, a, b graph.System) []dbstore.Ansiblex {
+	return []dbstore.Ansiblex{
+		{ID: id, Name: a.Name + " » " + b.Name + " - JB", SolarSystemID: a.ID},
+		{ID: id + 1, Name: b.Name + " » " + a.Name + " - JB", SolarSystemID: b.ID},
+	}
+}
+
+func newNetwork(t *testing.T, g graph.Graph, ans []dbstore.Ansiblex, mode Mode) *Network {
+	t.Helper()
+	r, err := routepkg.NewRouteFromGraph(g, dbstore.NewMemory(ans, nil, nil), nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return NewNetwork(r, mode)
+}
+
+// TestChokepointsLine проверяет цепочку: все внутренние системы — шарниры, все врата — мосты.
+func TestChokepointsLine(t *testing.T) {
+	g := lineGraph()
+	report, err := Chokepoints(context.Background(), newNetwork(t, g, nil, ModeGates))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := map[string]Chokepoint{}
+	for _, c := range report.Chokepoints {
+		got[c.System.Name] = c
+	}
+	want := map[string]Chokepoint{
+		"B": {Articulation: true, Isolated: 1, Betweenness: 3},
+		"C": {Articulation: true, Isolated: 2, Betweenness: 4},
+		"D": {Articulation: true, Isolated: 1, Betweenness: 3},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ожидалось %d узких мест, получено %+v", len(want), report.Chokepoints)
+	}
+	for name, w := range want {
+		c := got[name]
+		if c.Articulation != w.Articulation || c.Isolated != w.Isolated || c.Betweenness != w.Betweenness {
+			t.Errorf("%s: получено %+v, ожидалось %+v", name, c, w)
+		}
+	}
+	if report.Chokepoints[0].System.Name != "C" {
+		t.Errorf("первой должна быть система C, получено %s", report.Chokepoints[0].System.Name)
+	}
+	if len(report.Bridges) != 4 || report.Bridges[0].Isolated != 2 || report.Bridges[3].Isolated != 1 {
+		t.Errorf("неверные мосты: %+v", report.Bridges)
+	}
+
+	regions := report.ByRegion("", 1)
+	if len(regions) != 2 || regions[0].Region != "East" || len(regions[0].Chokepoints) != 1 {
+		t.Fatalf("неверная группировка: %+v", regions)
+	}
+	// мост C-D принадлежит обоим регионам
+	west := report.ByRegion("west", 0)
+	if len(west) != 1 || len(west[0].Chokepoints) != 2 || len(west[0].Bridges) != 3 {
+		t.Fatalf("неверный регион West: %+v", west)
+	}
+}
+
+// TestChokepointsAnsiblex проверяет, что Ansiblex замыкает цепочку в кольцо
+// и что параллельное соединение не считается мостом.
+func TestChokepointsAnsiblex(t *testing.T) {
+	g := lineGraph()
+	ans := append(ansiblexPair(100, g.Systems[0], g.Systems[4]), ansiblexPair(200, g.Systems[1], g.Systems[2])...)
+
+	report, err := Chokepoints(context.Background(), newNetwork(t, g, ans, ModeAnsiblex))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Bridges) != 0 {
+		t.Errorf("в кольце не должно быть мостов: %+v", report.Bridges)
+	}
+	for _, c := range report.Chokepoints {
+		if c.Articulation {
+			t.Errorf("в кольце не должно быть шарниров: %+v", c)
+		}
+	}
+
+	// в сети только из врат Ansiblex не учитываются
+	report, err = Chokepoints(context.Background(), newNetwork(t, g, ans, ModeGates))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Bridges) != 4 {
+		t.Errorf("ожидалось 4 моста, получено %+v", report.Bridges)
+	}
+}
+
+// TestChokepointsRandom сравнивает результат с полным перебором на случайных графах.
+func TestChokepointsRandom(t *testing.T) {
+	rnd := rand.New(rand.NewSource(5))
+	for iter := 0; iter < 20; iter++ {
+		g := graph.Graph{Regions: map[int]string{1: "R"}}
+		count := 8 + rnd.Intn(12)
+		for i := 1; i <= count; i++ {
+			g.Systems = append(g.Systems, graph.System{ID: i, Name: fmt.Sprintf("S%d", i), RegionID: 1})
+		}
+		for i := 0; i < count+rnd.Intn(count); i++ {
+			g.Connections = append(g.Connections, [2]int{1 + rnd.Intn(count), 1 + rnd.Intn(count)})
+		}
+		n := newNetwork(t, g, nil, ModeGates)
+		isolated, articulation, bridges := n.cuts()
+		betweenness, err := n.betweenness(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		nbrs := n.neighbours()
+		for v := 0; v < n.Len(); v++ {
+			want := bruteIsolated(nbrs, v)
+			if isolated[v] != want || articulation[v] != splits(nbrs, v) {
+				t.Fatalf("итерация %d, система %d: isolated=%d articulation=%v, ожидалось %d",
+					iter, v, isolated[v], articulation[v], want)
+			}
+			if math.Abs(betweenness[v]-bruteBetweenness(nbrs, v)) > 1e-9 {
+				t.Fatalf("итерация %d, система %d: betweenness=%f, ожидалось %f",
+					iter, v, betweenness[v], bruteBetweenness(nbrs, v))
+			}
+		}
+		isBridge := map[int]bool{}
+		for _, b := range bridges {
+			isBridge[b.edge] = true
+		}
+		for id, e := range n.edges {
+			if want := components(n, -1, id) > components(n, -1, -1); isBridge[id] != want {
+				t.Fatalf("итерация %d, соединение %d-%d: мост=%v, ожидалось %v", iter, e.A, e.B, isBridge[id], want)
+			}
+		}
+	}
+}
+
+// bfsFrom возвращает расстояния от s, не заходя в систему skip.
+func bfsFrom(nbrs [][]int32, s, skip int) []int {
+	dist := make([]int, len(nbrs))
+	for i := range dist {
+		dist[i] = -1
+	}
+	dist[s] = 0
+	queue := []int{s}
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		for _, u := range nbrs[v] {
+			if int(u) != skip && dist[u] < 0 {
+				dist[u] = dist[v] + 1
+				queue = append(queue, int(u))
+			}
+		}
+	}
+	return dist
+}
+
+// partSizes возвращает размеры компонент, на которые распадается компонента v без неё.
+func partSizes(nbrs [][]int32, v int) []int {
+	seen := map[int]bool{}
+	var sizes []int
+	for _, u := range nbrs[v] {
+		if seen[int(u)] {
+			continue
+		}
+		size := 0
+		for x, d := range bfsFrom(nbrs, int(u), v) {
+			if d >= 0 {
+				seen[x] = true
+				size++
+			}
+		}
+		sizes = append(sizes, size)
+	}
+	return sizes
+}
+
+// bruteIsolated возвращает число систем вне самой большой из частей.
+func bruteIsolated(nbrs [][]int32, v int) int {
+	sizes := partSizes(nbrs, v)
+	sum, largest := 0, 0
+	for _, s := range sizes {
+		sum += s
+		largest = max(largest, s)
+	}
+	return sum - largest
+}
+
+// splits сообщает, распадается ли компонента без системы v.
+func splits(nbrs [][]int32, v int) bool { return len(partSizes(nbrs, v)) > 1 }
+
+// countPaths возвращает число кратчайших путей от s до каждой системы.
+func countPaths(nbrs [][]int32, s int) ([]int, []float64) {
+	dist := bfsFrom(nbrs, s, -1)
+	sigma := make([]float64, len(nbrs))
+	sigma[s] = 1
+	for d := 1; d < len(nbrs); d++ {
+		for u := range nbrs {
+			if dist[u] != d {
+				continue
+			}
+			for _, v := range nbrs[u] {
+				if dist[v] == d-1 {
+					sigma[u] += sigma[v]
+				}
+			}
+		}
+	}
+	return dist, sigma
+}
+
+// bruteBetweenness перебирает все пары систем.
+func bruteBetweenness(nbrs [][]int32, v int) float64 {
+	dv, sv := countPaths(nbrs, v)
+	total := 0.0
+	for s := range nbrs {
+		ds, ss := countPaths(nbrs, s)
+		for t := s + 1; t < len(nbrs); t++ {
+			if s == v || t == v || ds[t] < 0 || ds[v] < 0 || dv[t] < 0 {
+				continue
+			}
+			if ds[v]+dv[t] == ds[t] {
+				total += ss[v] * sv[t] / ss[t]
+			}
+		}
+	}
+	return total
+}
+
+// components считает компоненты связности без системы skipNode и соединения skipEdge.
+func components(n *Network, skipNode, skipEdge int) int {
+	seen := make([]bool, n.Len())
+	count := 0
+	for s := 0; s < n.Len(); s++ {
+		if seen[s] || s == skipNode {
+			continue
+		}
+		count++
+		seen[s] = true
+		stack := []int{s}
+		for len(stack) > 0 {
+			v := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for _, e := range n.adj[v] {
+				if e.id != skipEdge && e.to != skipNode && !seen[e.to] {
+					seen[e.to] = true
+					stack = append(stack, e.to)
+				}
+			}
+		}
+	}
+	return count
+}
+
+// TestChokepointsCancelled проверяет прерывание расчёта по контексту.
+func TestChokepointsCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Chokepoints(ctx, newNetwork(t, lineGraph(), nil, ModeGates)); err != context.Canceled {
+		t.Fatalf("ожидалась context.Canceled, получено %v", err)
+	}
+}
@@ -0,0 +1,198 @@
+// Package analysis исследует структуру сети соединений маршрутизатора:
+// уязвимые системы и соединения, от которых зависит связность карты.
+package analysis
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tkhamez/eve-route-go/internal/graph"
+	routepkg "github.com/tkhamez/eve-route-go/internal/route"
+)
+
+// Mode определяет, какие соединения входят в сеть.
+type Mode string
+
+const (
+	// ModeGates — только звёздные врата.
+	ModeGates Mode = "gates"
+	// ModeAnsiblex — звёздные врата и Ansiblex.
+	ModeAnsiblex Mode = "ansiblex"
+	// ModeAll — вся сеть маршрутизатора: звёздные врата, Ansiblex
+	// и временные соединения. Используется в симуляции (см. Simulate).
+	ModeAll Mode = "all"
+)
+
+// ParseMode разбирает название режима; пустая строка означает ModeAnsiblex.
+func ParseMode(s string) (Mode, error) {
+	switch Mode(s) {
+	case "":
+		return ModeAnsiblex, nil
+	case ModeGates, ModeAnsiblex, ModeAll:
+		return Mode(s), nil
+	}
+	return "", fmt.Errorf("unknown network %q", s)
+}
+
+// includes сообщает, входит ли соединение указанного типа в сеть.
+func (m Mode) includes(t routepkg.WaypointType) bool {
+	switch t {
+	case routepkg.TypeStargate:
+		return true
+	case routepkg.TypeAnsiblex:
+		return m == ModeAnsiblex || m == ModeAll
+	case routepkg.TypeTemporary:
+		return m == ModeAll
+	}
+	return false
+}
+
+// Edge — ненаправленное соединение между системами с индексами A и B.
+type Edge struct {
+	A, B int
+	Type routepkg.WaypointType
+}
+
+// halfEdge — соединение со стороны одной из систем; id — номер в Network.edges.
+type halfEdge struct {
+	to, id int
+}
+
+// Network — неизменяемый снимок сети соединений маршрутизатора.
+// Системы нумеруются так же, как узлы route.Node (см. Node.Index).
+// Параллельные соединения разных типов хранятся отдельными рёбрами,
+// поэтому пара систем, связанная и вратами, и Ansiblex, не считается мостом.
+type Network struct {
+	mode    Mode
+	regions map[int]string
+	systems []graph.System
+	index   map[int]int
+	byName  map[string]int
+	edges   []Edge
+	adj     [][]halfEdge
+}
+
+// NewNetwork строит сеть по узлам маршрутизатора r; набор соединений задаёт mode.
+func NewNetwork(r *routepkg.Route, mode Mode) *Network {
+	nodes := r.Nodes()
+	n := &Network{
+		mode:    mode,
+		regions: r.Graph().Regions,
+		systems: make([]graph.System, len(nodes)),
+		index:   make(map[int]int, len(nodes)),
+		byName:  make(map[string]int, len(nodes)),
+		adj:     make([][]halfEdge, len(nodes)),
+	}
+	for i, node := range nodes {
+		n.systems[i] = node.Value
+		n.index[node.Value.ID] = i
+		n.byName[strings.ToLower(node.Value.Name)] = i
+	}
+	for _, node := range nodes {
+		for _, c := range node.Connections() {
+			// каждое соединение есть у обоих узлов, берём его один раз
+			if node.Index() < c.Node.Index() && mode.includes(c.Type) {
+				n.addEdge(node.Index(), c.Node.Index(), c.Type)
+			}
+		}
+	}
+	return n
+}
+
+func (n *Network) addEdge(a, b int, t routepkg.WaypointType) {
+	id := len(n.edges)
+	n.edges = append(n.edges, Edge{A: a, B: b, Type: t})
+	n.adj[a] = append(n.adj[a], halfEdge{to: b, id: id})
+	n.adj[b] = append(n.adj[b], halfEdge{to: a, id: id})
+}
+
+// Mode возвращает режим, в котором построена сеть.
+func (n *Network) Mode() Mode { return n.mode }
+
+// Len возвращает количество систем в сети.
+func (n *Network) Len() int { return len(n.systems) }
+
+// Index возвращает индекс системы по её ID.
+func (n *Network) Index(systemID int) (int, bool) {
+	i, ok := n.index[systemID]
+	return i, ok
+}
+
+// Lookup возвращает индекс системы по названию (без учёта регистра).
+func (n *Network) Lookup(name string) (int, bool) {
+	i, ok := n.byName[strings.ToLower(name)]
+	return i, ok
+}
+
+// ref описывает систему с индексом i.
+func (n *Network) ref(i int) SystemRef {
+	s := n.systems[i]
+	return SystemRef{ID: s.ID, Name: s.Name, RegionID: s.RegionID, Region: n.regions[s.RegionID], Security: s.Security}
+}
+
+// withChanges возвращает копию сети, в которой соединения removed (по номерам рёбер)
+// удалены, а между парами систем added проложены Ansiblex. Исходная сеть не изменяется.
+func (n *Network) withChanges(added [][2]int, removed map[int]bool) *Network {
+	c := &Network{
+		mode:    n.mode,
+		regions: n.regions,
+		systems: n.systems,
+		index:   n.index,
+		byName:  n.byName,
+		adj:     make([][]halfEdge, len(n.systems)),
+	}
+	for id, e := range n.edges {
+		if !removed[id] {
+			c.addEdge(e.A, e.B, e.Type)
+		}
+	}
+	for _, p := range added {
+		c.addEdge(p[0], p[1], routepkg.TypeAnsiblex)
+	}
+	return c
+}
+
+// distances возвращает число прыжков от системы from до всех систем (-1 — недостижима).
+func (n *Network) distances(from int) []int32 {
+	dist := make([]int32, len(n.systems))
+	for i := range dist {
+		dist[i] = -1
+	}
+	dist[from] = 0
+	queue := []int{from}
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		for _, e := range n.adj[v] {
+			if dist[e.to] < 0 {
+				dist[e.to] = dist[v] + 1
+				queue = append(queue, e.to)
+			}
+		}
+	}
+	return dist
+}
+
+// neighbours возвращает списки соседей без повторов для каждой системы.
+func (n *Network) neighbours() [][]int32 {
+	res := make([][]int32, len(n.adj))
+	for v, edges := range n.adj {
+		seen := make(map[int]bool, len(edges))
+		for _, e := range edges {
+			if !seen[e.to] {
+				seen[e.to] = true
+				res[v] = append(res[v], int32(e.to))
+			}
+		}
+	}
+	return res
+}
+
+// SystemRef — краткое описание системы в результатах анализа.
+type SystemRef struct {
+	ID       int     `json:"id"`
+	Name     string  `json:"name"`
+	RegionID int     `json:"regionId"`
+	Region   string  `json:"region"`
+	Security float64 `json:"security"`
+}
@@ -0,0 +1,269 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/tkhamez/eve-route-go/internal/capital"
+	"github.com/tkhamez/eve-route-go/internal/graph"
+	routepkg "github.com/tkhamez/eve-route-go/internal/route"
+)
+
+const (
+	// DefaultAnsiblexRange — максимальное расстояние между концами Ansiblex в световых годах.
+	DefaultAnsiblexRange = 6.0
+	// maxPlacementBudget ограничивает количество пар в одной рекомендации.
+	maxPlacementBudget = 20
+	// unreachableJumps — штраф за пару систем без связи; так соединение
+	// изолированных систем считается большим выигрышем.
+	unreachableJumps = 1000
+	// proposedLabel — подпись в названиях предлагаемых Ansiblex.
+	proposedLabel = "Proposed"
+)
+
+// Destination — важная система и её вес в оценке маршрутов.
+type Destination struct {
+	System string  `json:"system"`
+	Weight float64 `json:"weight"`
+}
+
+// Placement — параметры подбора новых пар Ansiblex.
+type Placement struct {
+	// Regions — регионы, в которых ставятся Ansiblex и из которых считаются маршруты.
+	Regions []string
+	// Budget — количество новых пар.
+	Budget int
+	// RangeLY — дальность Ansiblex; 0 означает DefaultAnsiblexRange.
+	RangeLY float64
+	// Destinations — системы, к которым нужно сократить путь; вес 0 считается равным 1.
+	Destinations []Destination
+}
+
+// Recommendation — предлагаемая пара Ansiblex.
+type Recommendation struct {
+	From       SystemRef `json:"from"`
+	To         SystemRef `json:"to"`
+	DistanceLY float64   `json:"distanceLy"`
+	// Names — названия обеих структур в формате, который понимает маршрутизатор.
+	Names [2]string `json:"names"`
+	// Gain — на сколько уменьшается средневзвешенное число прыжков.
+	Gain float64 `json:"gain"`
+}
+
+// Recommendations — результат подбора. Before и After — средневзвешенное число прыжков
+// от систем регионов до важных систем до и после установки всех предложенных пар.
+type Recommendations struct {
+	Before float64          `json:"before"`
+	After  float64          `json:"after"`
+	Pairs  []Recommendation `json:"pairs"`
+}
+
+// Recommend жадно подбирает до p.Budget пар Ansiblex, которые сильнее всего сокращают
+// средневзвешенное число прыжков от всех систем регионов до важных систем.
+// Концы пары — системы нулевой безопасности из выбранных регионов на расстоянии не
+// больше дальности Ansiblex (capital.Distance); в каждой системе может быть только
+// один Ansiblex, поэтому системы с существующими Ansiblex не предлагаются.
+// Сеть n должна строиться в режиме ModeAnsiblex и не изменяется.
+func Recommend(ctx context.Context, n *Network, p Placement) (Recommendations, error) {
+	if p.Budget <= 0 || p.Budget > maxPlacementBudget {
+		return Recommendations{}, fmt.Errorf("budget must be between 1 and %d", maxPlacementBudget)
+	}
+	rangeLY := p.RangeLY
+	if rangeLY == 0 {
+		rangeLY = DefaultAnsiblexRange
+	}
+	if rangeLY < 0 {
+		return Recommendations{}, fmt.Errorf("invalid range %.2f", rangeLY)
+	}
+	sources, err := n.regionSystems(p.Regions)
+	if err != nil {
+		return Recommendations{}, err
+	}
+	dests, weights, err := n.destinations(p.Destinations)
+	if err != nil {
+		return Recommendations{}, err
+	}
+
+	used := map[int]bool{}
+	for _, e := range n.edges {
+		if e.Type == routepkg.TypeAnsiblex {
+			used[e.A], used[e.B] = true, true
+		}
+	}
+	// candidates — позиции систем-кандидатов в sources
+	var candidates []int
+	for i, v := range sources {
+		s := n.systems[v]
+		if s.HasPosition() && s.Security < 0.05 && !used[v] {
+			candidates = append(candidates, i)
+		}
+	}
+	type pair struct {
+		a, b int // позиции в sources
+		ly   float64
+	}
+	var pairs []pair
+	for x, i := range candidates {
+		for _, j := range candidates[x+1:] {
+			ly := capital.Distance(capitalSystem(n.systems[sources[i]]), capitalSystem(n.systems[sources[j]]))
+			if ly <= rangeLY {
+				pairs = append(pairs, pair{a: i, b: j, ly: ly})
+			}
+		}
+	}
+	log.Printf("analysis: placement over %d systems, %d candidate pairs, %d destinations", len(sources), len(pairs), len(dests))
+
+	var totalWeight float64
+	for _, w := range weights {
+		totalWeight += w
+	}
+	norm := totalWeight * float64(len(sources))
+
+	res := Recommendations{Pairs: []Recommendation{}}
+	var added [][2]int
+	current := n
+	for step := 0; step < p.Budget; step++ {
+		// S[i][j] — прыжки между системами sources[i] и sources[j], D[d][i] — от важной системы d до sources[i]
+		S := make([][]int, len(sources))
+		for i, v := range sources {
+			if err := ctx.Err(); err != nil {
+				return Recommendations{}, err
+			}
+			S[i] = pick(current.distances(v), sources)
+		}
+		D := make([][]int, len(dests))
+		for d, v := range dests {
+			D[d] = pick(current.distances(v), sources)
+		}
+		var base float64
+		for d := range dests {
+			for i := range sources {
+				base += weights[d] * float64(D[d][i])
+			}
+		}
+		if step == 0 {
+			res.Before = base / norm
+		}
+		res.After = base / norm
+
+		best, bestGain := -1, 0.0
+		for k, pr := range pairs {
+			if k%256 == 0 {
+				if err := ctx.Err(); err != nil {
+					return Recommendations{}, err
+				}
+			}
+			if used[sources[pr.a]] || used[sources[pr.b]] || S[pr.a][pr.b] == 1 {
+				continue
+			}
+			var g float64
+			for d := range dests {
+				da, db := D[d][pr.a], D[d][pr.b]
+				for i := range sources {
+					old := D[d][i]
+					via := min(S[i][pr.a]+1+db, S[i][pr.b]+1+da)
+					if via < old {
+						g += weights[d] * float64(old-via)
+					}
+				}
+			}
+			if g > bestGain {
+				best, bestGain = k, g
+			}
+		}
+		if best < 0 {
+			break
+		}
+		pr := pairs[best]
+		a, b := sources[pr.a], sources[pr.b]
+		used[a], used[b] = true, true
+		added = append(added, [2]int{a, b})
+		current = n.withChanges(added, nil)
+		from, to := n.ref(a), n.ref(b)
+		res.Pairs = append(res.Pairs, Recommendation{
+			From:       from,
+			To:         to,
+			DistanceLY: pr.ly,
+			Names:      [2]string{graph.AnsiblexName(from.Name, to.Name, proposedLabel), graph.AnsiblexName(to.Name, from.Name, proposedLabel)},
+			Gain:       bestGain / norm,
+		})
+		res.After -= bestGain / norm
+	}
+	return res, nil
+}
+
+// regionSystems возвращает индексы систем указанных регионов, упорядоченные по ID.
+func (n *Network) regionSystems(names []string) ([]int, error) {
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no regions")
+	}
+	ids := map[int]bool{}
+	for _, name := range names {
+		found := false
+		for id, r := range n.regions {
+			if strings.EqualFold(r, name) {
+				ids[id] = true
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown region %q", name)
+		}
+	}
+	var res []int
+	for i, s := range n.systems {
+		if ids[s.RegionID] {
+			res = append(res, i)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return n.systems[res[i]].ID < n.systems[res[j]].ID })
+	if len(res) == 0 {
+		return nil, fmt.Errorf("no systems in regions %s", strings.Join(names, ", "))
+	}
+	return res, nil
+}
+
+// destinations возвращает индексы и веса важных систем.
+func (n *Network) destinations(list []Destination) ([]int, []float64, error) {
+	if len(list) == 0 {
+		return nil, nil, fmt.Errorf("no destinations")
+	}
+	var idx []int
+	var weights []float64
+	for _, d := range list {
+		i, ok := n.Lookup(d.System)
+		if !ok {
+			return nil, nil, fmt.Errorf("unknown system %q", d.System)
+		}
+		w := d.Weight
+		if w < 0 {
+			return nil, nil, fmt.Errorf("negative weight for %s", d.System)
+		}
+		if w == 0 {
+			w = 1
+		}
+		idx = append(idx, i)
+		weights = append(weights, w)
+	}
+	return idx, weights, nil
+}
+
+// pick возвращает расстояния до систем targets, заменяя недостижимые штрафом.
+func pick(dist []int32, targets []int) []int {
+	res := make([]int, len(targets))
+	for i, t := range targets {
+		if d := dist[t]; d >= 0 {
+			res[i] = int(d)
+		} else {
+			res[i] = unreachableJumps
+		}
+	}
+	return res
+}
+
+func capitalSystem(s graph.System) capital.System {
+	return capital.System{ID: s.ID, Name: s.Name, X: s.X, Y: s.Y, Z: s.Z}
+}
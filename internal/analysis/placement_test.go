@@ -0,0 +1,97 @@
+package analysis
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/tkhamez/eve-route-go/internal/graph"
+)
+
+// lyInMeters — световой год в метрах, как в пакете capital.
+const lyInMeters = 9.4607e15
+
+// spacedLine возвращает цепочку A-B-C-D-E с шагом 1 св. год в одном регионе.
+func spacedLine() graph.Graph {
+	g := lineGraph()
+	for i := range g.Systems {
+		g.Systems[i].RegionID = 1
+		// без нулевых координат: они означают, что положение неизвестно
+		g.Systems[i].X = float64(i+1) * lyInMeters
+	}
+	return g
+}
+
+// TestRecommend проверяет выбор лучшей пары и остановку, когда улучшать нечего.
+func TestRecommend(t *testing.T) {
+	n := newNetwork(t, spacedLine(), nil, ModeAnsiblex)
+	rec, err := Recommend(context.Background(), n, Placement{
+		Regions:      []string{"west"},
+		Budget:       2,
+		Destinations: []Destination{{System: "E"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rec.Pairs) != 1 {
+		t.Fatalf("ожидалась 1 пара, получено %+v", rec.Pairs)
+	}
+	p := rec.Pairs[0]
+	if p.From.Name != "A" || p.To.Name != "E" || math.Abs(p.DistanceLY-4) > 1e-9 {
+		t.Errorf("неверная пара: %+v", p)
+	}
+	if p.Names != [2]string{"A » E - Proposed", "E » A - Proposed"} {
+		t.Errorf("неверные названия: %v", p.Names)
+	}
+	if math.Abs(rec.Before-2) > 1e-9 || math.Abs(rec.After-1.2) > 1e-9 || math.Abs(p.Gain-0.8) > 1e-9 {
+		t.Errorf("неверная оценка: %+v", rec)
+	}
+}
+
+// TestRecommendConstraints проверяет дальность, безопасность и существующие Ansiblex.
+func TestRecommendConstraints(t *testing.T) {
+	g := spacedLine()
+	rec, err := Recommend(context.Background(), newNetwork(t, g, nil, ModeAnsiblex), Placement{
+		Regions:      []string{"West"},
+		Budget:       1,
+		RangeLY:      2.5,
+		Destinations: []Destination{{System: "E", Weight: 2}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rec.Pairs) != 1 || rec.Pairs[0].From.Name != "C" || rec.Pairs[0].To.Name != "E" {
+		t.Fatalf("ожидалась пара C-E, получено %+v", rec.Pairs)
+	}
+
+	// E в хайсеке, у D уже есть Ansiblex — лучший доступный вариант A-C
+	g.Systems[4].Security = 0.5
+	ans := ansiblexPair(1, g.Systems[3], g.Systems[1])
+	rec, err = Recommend(context.Background(), newNetwork(t, g, ans, ModeAnsiblex), Placement{
+		Regions:      []string{"West"},
+		Budget:       1,
+		Destinations: []Destination{{System: "E"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, p := range rec.Pairs {
+		for _, name := range []string{p.From.Name, p.To.Name} {
+			if name == "E" || name == "D" || name == "B" {
+				t.Fatalf("недопустимая пара: %+v", p)
+			}
+		}
+	}
+
+	n := newNetwork(t, g, nil, ModeAnsiblex)
+	for _, p := range []Placement{
+		{Regions: []string{"West"}, Budget: 0, Destinations: []Destination{{System: "E"}}},
+		{Regions: []string{"Nowhere"}, Budget: 1, Destinations: []Destination{{System: "E"}}},
+		{Regions: []string{"West"}, Budget: 1},
+		{Regions: []string{"West"}, Budget: 1, Destinations: []Destination{{System: "E", Weight: -1}}},
+	} {
+		if _, err := Recommend(context.Background(), n, p); err == nil {
+			t.Errorf("ожидалась ошибка для %+v", p)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package analysis
+
+import (
+	"context"
+	"sync"
+
+	routepkg "github.com/tkhamez/eve-route-go/internal/route"
+)
+
+// Reports хранит отчёты об узких местах (см. Chokepoints) для обеих сетей текущего
+// маршрутизатора. Расчёт долгий, поэтому он выполняется в фоне через Refresh,
+// а запросы получают только готовые отчёты.
+type Reports struct {
+	src routepkg.Source
+
+	mu sync.Mutex
+	// route — маршрутизатор, для которого посчитаны reports.
+	route   *routepkg.Route
+	reports map[Mode]Report
+	// cancel прерывает текущий пересчёт.
+	cancel context.CancelFunc
+}
+
+// NewReports создаёт хранилище отчётов для маршрутизатора src. Отчётов нет,
+// пока не выполнен Refresh.
+func NewReports(src routepkg.Source) *Reports {
+	return &Reports{src: src}
+}
+
+// Refresh пересчитывает отчёты для текущего маршрутизатора src и возвращается
+// после окончания расчёта. Предыдущий незавершённый пересчёт прерывается.
+func (r *Reports) Refresh(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	r.mu.Lock()
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.cancel = cancel
+	// маршрутизатор читается под блокировкой: последний начатый пересчёт
+	// считает самый свежий маршрутизатор, а все более ранние прерываются
+	route := r.src.Route()
+	r.mu.Unlock()
+
+	reports := map[Mode]Report{}
+	for _, mode := range []Mode{ModeGates, ModeAnsiblex} {
+		report, err := Chokepoints(ctx, NewNetwork(route, mode))
+		if err != nil {
+			return err
+		}
+		reports[mode] = report
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// пересчёт прерван более новым — его результат устарел
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.route, r.reports = route, reports
+	return nil
+}
+
+// Report возвращает отчёт для сети mode. ok равно false, если отчёт для текущего
+// маршрутизатора ещё не посчитан.
+func (r *Reports) Report(mode Mode) (report Report, ok bool) {
+	route := r.src.Route()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.route != route {
+		return Report{}, false
+	}
+	report, ok = r.reports[mode]
+	return report, ok
+}
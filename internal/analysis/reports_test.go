@@ -0,0 +1,55 @@
+package analysis
+
+import (
+	"context"
+	"testing"
+
+	dbstore "github.com/tkhamez/eve-route-go/internal/dbstore"
+	routepkg "github.com/tkhamez/eve-route-go/internal/route"
+)
+
+func TestReports(t *testing.T) {
+	g := lineGraph()
+	r, err := routepkg.NewRouteFromGraph(g, dbstore.NewMemory(nil, nil, nil), nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cache := routepkg.NewCache(r, 1)
+	reports := NewReports(cache)
+	if _, ok := reports.Report(ModeAnsiblex); ok {
+		t.Fatal("expected no report before Refresh")
+	}
+	if err := reports.Refresh(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	report, ok := reports.Report(ModeAnsiblex)
+	if !ok || len(report.Bridges) != 4 {
+		t.Fatalf("unexpected report: %v %+v", ok, report)
+	}
+
+	// после замены маршрутизатора старый отчёт не отдаётся
+	if err := cache.Rebuild(g, dbstore.NewMemory(ansiblexPair(1, g.Systems[0], g.Systems[4]), nil, nil)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := reports.Report(ModeAnsiblex); ok {
+		t.Fatal("expected no report for the rebuilt route")
+	}
+	if err := reports.Refresh(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report, ok := reports.Report(ModeAnsiblex); !ok || len(report.Bridges) != 0 {
+		t.Fatalf("Ansiblex network is a ring, expected no bridges: %v %+v", ok, report)
+	}
+	if report, ok := reports.Report(ModeGates); !ok || len(report.Bridges) != 4 {
+		t.Fatalf("unexpected gates report: %v %+v", ok, report)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := reports.Refresh(ctx); err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if _, ok := reports.Report(ModeGates); !ok {
+		t.Fatal("cancelled refresh must keep the current reports")
+	}
+}
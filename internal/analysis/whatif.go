@@ -0,0 +1,207 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+
+	routepkg "github.com/tkhamez/eve-route-go/internal/route"
+)
+
+// maxKeySystems ограничивает количество ключевых систем в одной симуляции.
+const maxKeySystems = 500
+
+// Scenario — гипотетические изменения сети Ansiblex. Сеть ModeGates содержит
+// только звёздные врата, поэтому Add и Remove для неё не допускаются.
+type Scenario struct {
+	// Add — пары систем, между которыми появляется Ansiblex.
+	Add []routepkg.ConnectedSystems
+	// Remove — пары систем, Ansiblex или временное соединение между которыми теряется.
+	Remove []routepkg.ConnectedSystems
+	// Systems — ключевые системы; сравниваются расстояния между всеми их парами.
+	Systems []string
+	// Limit — сколько пар вернуть в списках улучшений и ухудшений.
+	Limit int
+}
+
+// PairChange — изменение числа прыжков между двумя ключевыми системами.
+// Before и After равны -1, если системы не связаны.
+type PairChange struct {
+	From   SystemRef `json:"from"`
+	To     SystemRef `json:"to"`
+	Before int       `json:"before"`
+	After  int       `json:"after"`
+}
+
+// Stats — сводка расстояний между ключевыми системами.
+// Average и Max считаются по связанным парам.
+type Stats struct {
+	Average     float64 `json:"average"`
+	Max         int     `json:"max"`
+	Unreachable int     `json:"unreachable"`
+}
+
+// Simulation — результат сравнения сети до и после изменений.
+type Simulation struct {
+	Network  Mode         `json:"network"`
+	Pairs    int          `json:"pairs"`
+	Before   Stats        `json:"before"`
+	After    Stats        `json:"after"`
+	Improved []PairChange `json:"improved"`
+	Degraded []PairChange `json:"degraded"`
+}
+
+// Simulate применяет сценарий к копии сети n и сравнивает расстояния между
+// ключевыми системами. Ни сеть, ни маршрутизатор, ни хранилище не изменяются.
+func Simulate(ctx context.Context, n *Network, s Scenario) (Simulation, error) {
+	keys, err := n.lookupAll(s.Systems)
+	if err != nil {
+		return Simulation{}, err
+	}
+	if len(keys) < 2 {
+		return Simulation{}, fmt.Errorf("at least two key systems are required")
+	}
+	if len(keys) > maxKeySystems {
+		return Simulation{}, fmt.Errorf("too many key systems: %d (max %d)", len(keys), maxKeySystems)
+	}
+	if n.mode == ModeGates && (len(s.Add) > 0 || len(s.Remove) > 0) {
+		return Simulation{}, fmt.Errorf("network %q contains only stargates, add and remove are not supported", n.mode)
+	}
+	var added [][2]int
+	for _, c := range s.Add {
+		p, err := n.lookupPair(c)
+		if err != nil {
+			return Simulation{}, err
+		}
+		added = append(added, p)
+	}
+	removed := map[int]bool{}
+	for _, c := range s.Remove {
+		p, err := n.lookupPair(c)
+		if err != nil {
+			return Simulation{}, err
+		}
+		found := false
+		for _, e := range n.adj[p[0]] {
+			if t := n.edges[e.id].Type; e.to == p[1] && t != routepkg.TypeStargate {
+				removed[e.id] = true
+				found = true
+			}
+		}
+		if !found {
+			return Simulation{}, fmt.Errorf("no Ansiblex or temporary connection between %s and %s", c.System1, c.System2)
+		}
+	}
+	log.Printf("analysis: what-if with %d added and %d removed connections for %d systems", len(added), len(removed), len(keys))
+
+	after := n.withChanges(added, removed)
+	var changes []PairChange
+	var before, afterStats statsAcc
+	for i, a := range keys {
+		if err := ctx.Err(); err != nil {
+			return Simulation{}, err
+		}
+		d1 := n.distances(a)
+		d2 := after.distances(a)
+		for _, b := range keys[i+1:] {
+			pc := PairChange{From: n.ref(a), To: n.ref(b), Before: int(d1[b]), After: int(d2[b])}
+			before.add(pc.Before)
+			afterStats.add(pc.After)
+			changes = append(changes, pc)
+		}
+	}
+
+	res := Simulation{
+		Network:  n.mode,
+		Pairs:    len(changes),
+		Before:   before.stats(),
+		After:    afterStats.stats(),
+		Improved: []PairChange{},
+		Degraded: []PairChange{},
+	}
+	limit := s.Limit
+	if limit <= 0 {
+		limit = len(changes)
+	}
+	sort.SliceStable(changes, func(i, j int) bool { return gain(changes[i]) > gain(changes[j]) })
+	for _, c := range changes {
+		if len(res.Improved) < limit && gain(c) > 0 {
+			res.Improved = append(res.Improved, c)
+		}
+	}
+	for i := len(changes) - 1; i >= 0 && len(res.Degraded) < limit; i-- {
+		if gain(changes[i]) < 0 {
+			res.Degraded = append(res.Degraded, changes[i])
+		}
+	}
+	return res, nil
+}
+
+// gain возвращает, на сколько прыжков сократился путь. Появление связи считается
+// самым большим улучшением, потеря — самым большим ухудшением.
+func gain(c PairChange) int {
+	const unreachable = 1 << 20
+	before, after := c.Before, c.After
+	if before < 0 {
+		before = unreachable
+	}
+	if after < 0 {
+		after = unreachable
+	}
+	return before - after
+}
+
+type statsAcc struct {
+	sum, count, max, unreachable int
+}
+
+func (a *statsAcc) add(d int) {
+	if d < 0 {
+		a.unreachable++
+		return
+	}
+	a.sum += d
+	a.count++
+	a.max = max(a.max, d)
+}
+
+func (a statsAcc) stats() Stats {
+	s := Stats{Max: a.max, Unreachable: a.unreachable}
+	if a.count > 0 {
+		s.Average = float64(a.sum) / float64(a.count)
+	}
+	return s
+}
+
+// lookupAll возвращает индексы систем без повторов в порядке перечисления.
+func (n *Network) lookupAll(names []string) ([]int, error) {
+	var res []int
+	seen := map[int]bool{}
+	for _, name := range names {
+		i, ok := n.Lookup(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown system %q", name)
+		}
+		if !seen[i] {
+			seen[i] = true
+			res = append(res, i)
+		}
+	}
+	return res, nil
+}
+
+func (n *Network) lookupPair(c routepkg.ConnectedSystems) ([2]int, error) {
+	a, ok := n.Lookup(c.System1)
+	if !ok {
+		return [2]int{}, fmt.Errorf("unknown system %q", c.System1)
+	}
+	b, ok := n.Lookup(c.System2)
+	if !ok {
+		return [2]int{}, fmt.Errorf("unknown system %q", c.System2)
+	}
+	if a == b {
+		return [2]int{}, fmt.Errorf("cannot connect %s to itself", c.System1)
+	}
+	return [2]int{a, b}, nil
+}
@@ -0,0 +1,139 @@
+package analysis
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	dbstore "github.com/tkhamez/eve-route-go/internal/dbstore"
+	routepkg "github.com/tkhamez/eve-route-go/internal/route"
+)
+
+// TestSimulateAdd проверяет эффект нового Ansiblex на цепочке A-B-C-D-E.
+func TestSimulateAdd(t *testing.T) {
+	n := newNetwork(t, lineGraph(), nil, ModeAnsiblex)
+	sim, err := Simulate(context.Background(), n, Scenario{
+		Add:     []routepkg.ConnectedSystems{{System1: "a", System2: "E"}},
+		Systems: []string{"A", "C", "E", "A"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sim.Pairs != 3 {
+		t.Fatalf("ожидалось 3 пары, получено %d", sim.Pairs)
+	}
+	if math.Abs(sim.Before.Average-8.0/3) > 1e-9 || sim.Before.Max != 4 {
+		t.Errorf("неверная статистика до: %+v", sim.Before)
+	}
+	if math.Abs(sim.After.Average-5.0/3) > 1e-9 || sim.After.Max != 2 {
+		t.Errorf("неверная статистика после: %+v", sim.After)
+	}
+	if len(sim.Improved) != 1 || sim.Improved[0].From.Name != "A" || sim.Improved[0].To.Name != "E" ||
+		sim.Improved[0].Before != 4 || sim.Improved[0].After != 1 {
+		t.Errorf("неверные улучшения: %+v", sim.Improved)
+	}
+	if len(sim.Degraded) != 0 {
+		t.Errorf("неожиданные ухудшения: %+v", sim.Degraded)
+	}
+	// исходная сеть не изменилась
+	if d := n.distances(0); d[4] != 4 {
+		t.Errorf("исходная сеть изменена: A-E = %d", d[4])
+	}
+}
+
+// TestSimulateRemove проверяет потерю Ansiblex и то, что данные маршрутизатора не меняются.
+func TestSimulateRemove(t *testing.T) {
+	g := lineGraph()
+	r, err := routepkg.NewRouteFromGraph(g, dbstore.NewMemory(ansiblexPair(1, g.Systems[0], g.Systems[4]), nil, nil), nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sim, err := Simulate(context.Background(), NewNetwork(r, ModeAnsiblex), Scenario{
+		Remove:  []routepkg.ConnectedSystems{{System1: "E", System2: "A"}},
+		Systems: []string{"A", "B", "E"},
+		Limit:   1,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sim.Degraded) != 1 || sim.Degraded[0].Before != 1 || sim.Degraded[0].After != 4 {
+		t.Errorf("неверные ухудшения: %+v", sim.Degraded)
+	}
+	paths, err := r.Find(context.Background(), "A", "E")
+	if err != nil || len(paths) != 1 || len(paths[0]) != 2 {
+		t.Fatalf("маршрутизатор изменён: %v %v", paths, err)
+	}
+
+	for _, s := range []Scenario{
+		{Remove: []routepkg.ConnectedSystems{{System1: "A", System2: "B"}}, Systems: []string{"A", "B"}},
+		{Add: []routepkg.ConnectedSystems{{System1: "A", System2: "Nowhere"}}, Systems: []string{"A", "B"}},
+		{Systems: []string{"A"}},
+	} {
+		if _, err := Simulate(context.Background(), NewNetwork(r, ModeAnsiblex), s); err == nil {
+			t.Errorf("ожидалась ошибка для %+v", s)
+		}
+	}
+}
+
+// TestSimulateDisconnect проверяет учёт пар, потерявших связь.
+func TestSimulateDisconnect(t *testing.T) {
+	g := lineGraph()
+	g.Connections = g.Connections[:3] // E связана с остальными только через Ansiblex
+	ans := ansiblexPair(1, g.Systems[3], g.Systems[4])
+	r, err := routepkg.NewRouteFromGraph(g, dbstore.NewMemory(ans, nil, nil), nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sim, err := Simulate(context.Background(), NewNetwork(r, ModeAnsiblex), Scenario{
+		Remove:  []routepkg.ConnectedSystems{{System1: "D", System2: "E"}},
+		Systems: []string{"A", "D", "E"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sim.After.Unreachable != 2 || sim.After.Max != 3 || len(sim.Degraded) != 2 || sim.Degraded[0].After != -1 {
+		t.Errorf("неверный результат: %+v", sim)
+	}
+}
+
+// TestSimulateTemporary проверяет, что временные соединения входят в сеть ModeAll и их можно удалить.
+func TestSimulateTemporary(t *testing.T) {
+	g := lineGraph()
+	temps := []dbstore.TemporaryConnection{{System1ID: g.Systems[0].ID, System2ID: g.Systems[4].ID}}
+	r, err := routepkg.NewRouteFromGraph(g, dbstore.NewMemory(nil, temps, nil), nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	scenario := Scenario{
+		Remove:  []routepkg.ConnectedSystems{{System1: "A", System2: "E"}},
+		Systems: []string{"A", "E"},
+	}
+	sim, err := Simulate(context.Background(), NewNetwork(r, ModeAll), scenario)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sim.Before.Max != 1 || sim.After.Max != 4 || len(sim.Degraded) != 1 {
+		t.Errorf("неверный результат: %+v", sim)
+	}
+	// в сети ModeAnsiblex временных соединений нет
+	if _, err := Simulate(context.Background(), NewNetwork(r, ModeAnsiblex), scenario); err == nil {
+		t.Error("ожидалась ошибка: временное соединение не входит в сеть ansiblex")
+	}
+}
+
+// TestSimulateGates проверяет, что сеть только из врат нельзя изменять.
+func TestSimulateGates(t *testing.T) {
+	n := newNetwork(t, lineGraph(), nil, ModeGates)
+	for _, s := range []Scenario{
+		{Add: []routepkg.ConnectedSystems{{System1: "A", System2: "E"}}, Systems: []string{"A", "E"}},
+		{Remove: []routepkg.ConnectedSystems{{System1: "A", System2: "B"}}, Systems: []string{"A", "E"}},
+	} {
+		if _, err := Simulate(context.Background(), n, s); err == nil {
+			t.Errorf("ожидалась ошибка для %+v", s)
+		}
+	}
+	sim, err := Simulate(context.Background(), n, Scenario{Systems: []string{"A", "E"}})
+	if err != nil || sim.Before.Max != 4 || sim.After.Max != 4 {
+		t.Fatalf("неверный результат: %+v %v", sim, err)
+	}
+}
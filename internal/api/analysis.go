@@ -0,0 +1,157 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/tkhamez/eve-route-go/internal/analysis"
+	routepkg "github.com/tkhamez/eve-route-go/internal/route"
+)
+
+// defaultChokepointLimit — количество записей на регион по умолчанию.
+const defaultChokepointLimit = 10
+
+// chokepointRetryAfter — через сколько секунд повторить запрос, пока отчёт считается.
+const chokepointRetryAfter = 10
+
+// NewChokepointHandler возвращает HTTP-обработчик анализа узких мест сети.
+// Параметры запроса: network — "gates" (только врата) или "ansiblex"
+// (врата и Ansiblex, по умолчанию), region — название региона,
+// limit — количество узких мест и мостов на регион.
+// Отчёты считаются в фоне (см. analysis.Reports); пока отчёт для текущего
+// маршрутизатора не готов, возвращается 503 с заголовком Retry-After.
+func NewChokepointHandler(reports *analysis.Reports) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		q := req.URL.Query()
+		mode, err := analysis.ParseMode(q.Get("network"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		// отчёты считаются только для врат и для врат с Ansiblex
+		if mode == analysis.ModeAll {
+			http.Error(w, fmt.Sprintf("network %q is not supported", mode), http.StatusBadRequest)
+			return
+		}
+		limit := defaultChokepointLimit
+		if l := q.Get("limit"); l != "" {
+			n, err := strconv.Atoi(l)
+			if err != nil || n <= 0 {
+				http.Error(w, "invalid limit", http.StatusBadRequest)
+				return
+			}
+			limit = n
+		}
+
+		report, ok := reports.Report(mode)
+		if !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(chokepointRetryAfter))
+			http.Error(w, "analysis in progress", http.StatusServiceUnavailable)
+			return
+		}
+
+		regions := report.ByRegion(q.Get("region"), limit)
+		if q.Get("region") != "" && len(regions) == 0 {
+			http.NotFound(w, req)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"network": mode, "regions": regions})
+	}
+}
+
+// whatIfRequest — тело запроса симуляции изменений сети Ansiblex.
+type whatIfRequest struct {
+	Network string                      `json:"network"`
+	Systems []string                    `json:"systems"`
+	Add     []routepkg.ConnectedSystems `json:"add"`
+	Remove  []routepkg.ConnectedSystems `json:"remove"`
+	Limit   int                         `json:"limit"`
+}
+
+// NewWhatIfHandler возвращает HTTP-обработчик симуляции изменений сети Ansiblex.
+// Тело запроса — JSON с ключевыми системами (systems), добавляемыми (add)
+// и удаляемыми (remove) парами систем, network — "gates", "ansiblex" или "all"
+// (врата, Ansiblex и временные соединения, по умолчанию). Изменения применяются
+// к копии сети и нигде не сохраняются. Исходная сеть — текущий маршрутизатор src.
+func NewWhatIfHandler(src routepkg.Source) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		var body whatIfRequest
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		// по умолчанию симуляция идёт по всей сети, включая временные соединения
+		mode := analysis.ModeAll
+		if body.Network != "" {
+			var err error
+			if mode, err = analysis.ParseMode(body.Network); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		if body.Limit < 0 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		if body.Limit == 0 {
+			body.Limit = defaultChokepointLimit
+		}
+		sim, err := analysis.Simulate(req.Context(), analysis.NewNetwork(src.Route(), mode), analysis.Scenario{
+			Add:     body.Add,
+			Remove:  body.Remove,
+			Systems: body.Systems,
+			Limit:   body.Limit,
+		})
+		if err != nil {
+			if req.Context().Err() != nil {
+				http.Error(w, err.Error(), http.StatusServiceUnavailable)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(sim)
+	}
+}
+
+// placementRequest — тело запроса подбора новых пар Ansiblex.
+type placementRequest struct {
+	Regions      []string               `json:"regions"`
+	Budget       int                    `json:"budget"`
+	RangeLY      float64                `json:"rangeLy"`
+	Destinations []analysis.Destination `json:"destinations"`
+}
+
+// NewPlacementHandler возвращает HTTP-обработчик, подбирающий места для новых пар Ansiblex.
+// Тело запроса — JSON с регионами (regions), количеством пар (budget),
+// дальностью в световых годах (rangeLy) и важными системами с весами (destinations).
+// Текущая сеть берётся из маршрутизатора src.
+func NewPlacementHandler(src routepkg.Source) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		var body placementRequest
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		rec, err := analysis.Recommend(req.Context(), analysis.NewNetwork(src.Route(), analysis.ModeAnsiblex), analysis.Placement{
+			Regions:      body.Regions,
+			Budget:       body.Budget,
+			RangeLY:      body.RangeLY,
+			Destinations: body.Destinations,
+		})
+		if err != nil {
+			if req.Context().Err() != nil {
+				http.Error(w, err.Error(), http.StatusServiceUnavailable)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(rec)
+	}
+}
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tkhamez/eve-route-go/internal/analysis"
+	dbstore "github.com/tkhamez/eve-route-go/internal/dbstore"
+	"github.com/tkhamez/eve-route-go/internal/graph"
+	routepkg "github.com/tkhamez/eve-route-go/internal/route"
+)
+
+func TestNewChokepointHandler(t *testing.T) {
+	g := graph.Graph{
+		Systems: []graph.System{
+			{ID: 1, Name: "A", RegionID: 1},
+			{ID: 2, Name: "B", RegionID: 1},
+			{ID: 3, Name: "C", RegionID: 1},
+		},
+		Connections: [][2]int{{1, 2}, {2, 3}},
+		Regions:     map[int]string{1: "Line"},
+	}
+	r, err := routepkg.NewRouteFromGraph(g, dbstore.NewMemory(nil, nil, nil), nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	reports := analysis.NewReports(r)
+	h := NewChokepointHandler(reports)
+
+	// отчёт ещё не посчитан
+	rr := httptest.NewRecorder()
+	h(rr, httptest.NewRequest(http.MethodGet, "/api/analysis/chokepoints", nil))
+	if rr.Code != http.StatusServiceUnavailable || rr.Header().Get("Retry-After") == "" {
+		t.Fatalf("expected 503 with Retry-After, got %d", rr.Code)
+	}
+	if err := reports.Refresh(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rr = httptest.NewRecorder()
+	h(rr, httptest.NewRequest(http.MethodGet, "/api/analysis/chokepoints?network=gates&region=line", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	var resp struct {
+		Network analysis.Mode           `json:"network"`
+		Regions []analysis.RegionReport `json:"regions"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Network != analysis.ModeGates || len(resp.Regions) != 1 {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	c := resp.Regions[0].Chokepoints
+	if len(c) != 1 || c[0].System.Name != "B" || !c[0].Articulation || len(resp.Regions[0].Bridges) != 2 {
+		t.Fatalf("unexpected chokepoints: %+v", resp.Regions[0])
+	}
+
+	for _, url := range []string{"/api/analysis/chokepoints?network=wormholes", "/api/analysis/chokepoints?network=all", "/api/analysis/chokepoints?limit=0"} {
+		rr = httptest.NewRecorder()
+		h(rr, httptest.NewRequest(http.MethodGet, url, nil))
+		if rr.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", url, rr.Code)
+		}
+	}
+	rr = httptest.NewRecorder()
+	h(rr, httptest.NewRequest(http.MethodGet, "/api/analysis/chokepoints?region=Delve", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rr.Code)
+	}
+}
+
+func TestNewWhatIfHandler(t *testing.T) {
+	r, err := routepkg.NewRoute(dbstore.NewMemory(nil, nil, nil), nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	h := NewWhatIfHandler(r)
+
+	body := `{"systems":["Alpha","Beta","Gamma"],"remove":[],"add":[{"system1":"Alpha","system2":"Gamma"}]}`
+	rr := httptest.NewRecorder()
+	h(rr, httptest.NewRequest(http.MethodPost, "/api/whatif", strings.NewReader(body)))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body)
+	}
+	var sim analysis.Simulation
+	if err := json.NewDecoder(rr.Body).Decode(&sim); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if sim.Network != analysis.ModeAll || sim.Pairs != 3 || sim.Before.Max != 1 || len(sim.Improved) != 0 {
+		t.Fatalf("unexpected simulation: %+v", sim)
+	}
+
+	for _, body := range []string{`{`, `{"systems":["Alpha","Nowhere"]}`, `{"systems":["Alpha","Beta"],"network":"x"}`} {
+		rr = httptest.NewRecorder()
+		h(rr, httptest.NewRequest(http.MethodPost, "/api/whatif", strings.NewReader(body)))
+		if rr.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", body, rr.Code)
+		}
+	}
+}
+
+func TestNewPlacementHandler(t *testing.T) {
+	const ly = 9.4607e15
+	g := graph.Graph{
+		Systems: []graph.System{
+			{ID: 1, Name: "A", RegionID: 1, X: ly},
+			{ID: 2, Name: "B", RegionID: 1, X: 2 * ly},
+			{ID: 3, Name: "C", RegionID: 1, X: 3 * ly},
+		},
+		Connections: [][2]int{{1, 2}, {2, 3}},
+		Regions:     map[int]string{1: "Line"},
+	}
+	r, err := routepkg.NewRouteFromGraph(g, dbstore.NewMemory(nil, nil, nil), nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	h := NewPlacementHandler(r)
+
+	body := `{"regions":["Line"],"budget":1,"destinations":[{"system":"C","weight":1}]}`
+	rr := httptest.NewRecorder()
+	h(rr, httptest.NewRequest(http.MethodPost, "/api/analysis/ansiblex-placement", strings.NewReader(body)))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body)
+	}
+	var rec analysis.Recommendations
+	if err := json.NewDecoder(rr.Body).Decode(&rec); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(rec.Pairs) != 1 || rec.Pairs[0].From.Name != "A" || rec.Pairs[0].To.Name != "C" {
+		t.Fatalf("unexpected recommendation: %+v", rec)
+	}
+
+	rr = httptest.NewRecorder()
+	h(rr, httptest.NewRequest(http.MethodPost, "/api/analysis/ansiblex-placement", strings.NewReader(`{"regions":["Line"]}`)))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rr.Code)
+	}
+}
@@ -1,14 +1,19 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"log"
 	"net/http"
+	"sort"
 	"strconv"
 	"sync"
 	"time"
 
 	"github.com/gorilla/mux"
+
+	dbstore "github.com/tkhamez/eve-route-go/internal/dbstore"
+	"github.com/tkhamez/eve-route-go/internal/graph"
 )
 
 // Ansiblex represents a permanent jump bridge.
@@ -29,10 +34,11 @@ type TempConnection struct {
 
 // Store keeps Ansiblex and temporary connections in memory.
 type Store struct {
-	mu       sync.Mutex
-	nextID   int
-	ansiblex map[int]Ansiblex
-	temp     map[int]TempConnection
+	mu        sync.Mutex
+	nextID    int
+	ansiblex  map[int]Ansiblex
+	temp      map[int]TempConnection
+	listeners []func()
 }
 
 // NewStore creates a new in-memory store.
@@ -58,6 +64,98 @@ func RegisterAnsiblexRoutes(r *mux.Router, token string) *Store {
 	return s
 }
 
+// OnChange registers a function that is called after any Ansiblex or
+// temporary connection has been created, updated or deleted.
+func (s *Store) OnChange(fn func()) {
+	s.mu.Lock()
+	s.listeners = append(s.listeners, fn)
+	s.mu.Unlock()
+}
+
+// Connections returns a connection source for the route planner that combines
+// the connections of base with the ones managed by s. Systems are looked up
+// by name in g; connections with unknown systems are skipped.
+func (s *Store) Connections(base dbstore.ConnectionStore, g graph.Graph) dbstore.ConnectionStore {
+	return &liveConnections{base: base, store: s, helper: graph.NewHelper(g)}
+}
+
+type liveConnections struct {
+	base   dbstore.ConnectionStore
+	store  *Store
+	helper *graph.Helper
+}
+
+func (c *liveConnections) Ansiblexes(ctx context.Context) ([]dbstore.Ansiblex, error) {
+	res, err := c.base.Ansiblexes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res = append([]dbstore.Ansiblex(nil), res...)
+	c.store.mu.Lock()
+	defer c.store.mu.Unlock()
+	for _, id := range sortedIDs(c.store.ansiblex) {
+		a := c.store.ansiblex[id]
+		from, to := c.helper.FindSystemByName(a.From), c.helper.FindSystemByName(a.To)
+		if from == nil || to == nil {
+			log.Printf("ansiblex %d skipped: unknown system %q or %q", a.ID, a.From, a.To)
+			continue
+		}
+		regionID := from.RegionID
+		res = append(res, dbstore.Ansiblex{
+			ID:            int64(a.ID),
+			Name:          graph.AnsiblexName(from.Name, to.Name, a.Name),
+			SolarSystemID: from.ID,
+			RegionID:      &regionID,
+		})
+	}
+	return res, nil
+}
+
+func (c *liveConnections) TemporaryConnections(ctx context.Context) ([]dbstore.TemporaryConnection, error) {
+	res, err := c.base.TemporaryConnections(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res = append([]dbstore.TemporaryConnection(nil), res...)
+	c.store.mu.Lock()
+	defer c.store.mu.Unlock()
+	for _, id := range sortedIDs(c.store.temp) {
+		t := c.store.temp[id]
+		from, to := c.helper.FindSystemByName(t.From), c.helper.FindSystemByName(t.To)
+		if from == nil || to == nil {
+			log.Printf("temp connection %d skipped: unknown system %q or %q", t.ID, t.From, t.To)
+			continue
+		}
+		tc := dbstore.TemporaryConnection{System1ID: from.ID, System2ID: to.ID}
+		if !t.Expires.IsZero() {
+			expires := t.Expires
+			tc.Expires = &expires
+		}
+		res = append(res, tc)
+	}
+	return res, nil
+}
+
+// sortedIDs returns the keys of m in ascending order, so that the route
+// planner is built the same way regardless of map iteration order.
+func sortedIDs[T any](m map[int]T) []int {
+	ids := make([]int, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+func (s *Store) changed() {
+	s.mu.Lock()
+	listeners := append([]func(){}, s.listeners...)
+	s.mu.Unlock()
+	for _, fn := range listeners {
+		fn()
+	}
+}
+
 func (s *Store) auth(token string, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Header.Get("Authorization") != "Bearer "+token {
@@ -90,6 +188,7 @@ func (s *Store) createAnsiblex(w http.ResponseWriter, r *http.Request) {
 	s.ansiblex[a.ID] = a
 	s.mu.Unlock()
 	log.Printf("ansiblex created: %d", a.ID)
+	s.changed()
 	w.WriteHeader(http.StatusCreated)
 	_ = json.NewEncoder(w).Encode(a)
 }
@@ -115,6 +214,7 @@ func (s *Store) updateAnsiblex(w http.ResponseWriter, r *http.Request) {
 	s.ansiblex[id] = a
 	s.mu.Unlock()
 	log.Printf("ansiblex updated: %d", id)
+	s.changed()
 	_ = json.NewEncoder(w).Encode(a)
 }
 
@@ -128,6 +228,7 @@ func (s *Store) deleteAnsiblex(w http.ResponseWriter, r *http.Request) {
 	delete(s.ansiblex, id)
 	s.mu.Unlock()
 	log.Printf("ansiblex deleted: %d", id)
+	s.changed()
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -153,6 +254,7 @@ func (s *Store) createTemp(w http.ResponseWriter, r *http.Request) {
 	s.temp[t.ID] = t
 	s.mu.Unlock()
 	log.Printf("temp connection created: %d", t.ID)
+	s.changed()
 	w.WriteHeader(http.StatusCreated)
 	_ = json.NewEncoder(w).Encode(t)
 }
@@ -178,6 +280,7 @@ func (s *Store) updateTemp(w http.ResponseWriter, r *http.Request) {
 	s.temp[id] = t
 	s.mu.Unlock()
 	log.Printf("temp connection updated: %d", id)
+	s.changed()
 	_ = json.NewEncoder(w).Encode(t)
 }
 
@@ -191,5 +294,6 @@ func (s *Store) deleteTemp(w http.ResponseWriter, r *http.Request) {
 	delete(s.temp, id)
 	s.mu.Unlock()
 	log.Printf("temp connection deleted: %d", id)
+	s.changed()
 	w.WriteHeader(http.StatusNoContent)
 }
@@ -8,6 +8,9 @@ import (
 	"testing"
 
 	"github.com/gorilla/mux"
+	dbstore "github.com/tkhamez/eve-route-go/internal/dbstore"
+	"github.com/tkhamez/eve-route-go/internal/graph"
+	routepkg "github.com/tkhamez/eve-route-go/internal/route"
 )
 
 func TestAnsiblexAuth(t *testing.T) {
@@ -55,3 +58,82 @@ func TestTempAuth(t *testing.T) {
 		t.Fatalf("expected 401, got %d", w.Code)
 	}
 }
+
+func TestStoreOnChange(t *testing.T) {
+	r := mux.NewRouter()
+	s := RegisterAnsiblexRoutes(r, "token")
+	calls := 0
+	s.OnChange(func() { calls++ })
+
+	body := bytes.NewBufferString(`{"from":"X","to":"Y"}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/temp", body)
+	req.Header.Set("Authorization", "Bearer token")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/temp/1", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if calls != 2 {
+		t.Fatalf("expected 2 change notifications, got %d", calls)
+	}
+}
+
+func TestAnsiblexRebuildsRoute(t *testing.T) {
+	g := graph.Graph{
+		Systems: []graph.System{
+			{ID: 1, Name: "A", RegionID: 1}, {ID: 2, Name: "B", RegionID: 1},
+			{ID: 3, Name: "C", RegionID: 1}, {ID: 4, Name: "D", RegionID: 1},
+		},
+		Connections: [][2]int{{1, 2}, {2, 3}, {3, 4}},
+		Regions:     map[int]string{1: "Region"},
+	}
+	r := mux.NewRouter()
+	s := RegisterAnsiblexRoutes(r, "token")
+	connections := s.Connections(dbstore.NewMemory(nil, nil, nil), g)
+	planner, err := routepkg.NewRouteFromGraph(g, connections, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cache := routepkg.NewCache(planner, 10)
+	s.OnChange(func() {
+		if err := cache.Rebuild(g, connections); err != nil {
+			t.Errorf("rebuild: %v", err)
+		}
+	})
+	r.HandleFunc("/api/route/{from}/{to}", NewRouteHandler(cache, nil)).Methods("GET")
+
+	route := func() []routepkg.Waypoint {
+		t.Helper()
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/route/A/D", nil))
+		var resp struct {
+			Routes [][]routepkg.Waypoint `json:"routes"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil || len(resp.Routes) == 0 {
+			t.Fatalf("unexpected response %d: %v", w.Code, err)
+		}
+		return resp.Routes[0]
+	}
+	if got := route(); len(got) != 4 {
+		t.Fatalf("expected 4 systems via stargates, got %d", len(got))
+	}
+
+	body := bytes.NewBufferString(`{"name":"Bridge","from":"A","to":"D"}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/ansiblex", body)
+	req.Header.Set("Authorization", "Bearer token")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", w.Code)
+	}
+
+	got := route()
+	if len(got) != 2 || got[0].ConnectionType == nil || *got[0].ConnectionType != routepkg.TypeAnsiblex {
+		t.Fatalf("expected route via the new Ansiblex, got %+v", got)
+	}
+}
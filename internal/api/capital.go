@@ -0,0 +1,312 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/tkhamez/eve-route-go/internal/capital"
+)
+
+// NewCapitalHandler возвращает HTTP-обработчик маршрута капитального корабля.
+// Параметры запроса: start и end — названия систем, ship — класс корабля
+// (см. capital.Ships), jdc — уровень навыка Jump Drive Calibration (по умолчанию 5),
+// fatigue — усталость пилота в минутах перед первым прыжком (по умолчанию 0),
+// race — раса корабля для выбора изотопа, jfc и hull — уровни навыков
+// Jump Fuel Conservation и корпуса (по умолчанию 5), optimize — критерий выбора
+// маршрута (capital.Optimize, по умолчанию наименьшее число прыжков), avoid — системы
+// и регионы через запятую, которые нельзя использовать как промежуточные точки,
+// prefer — системы через запятую, в которых предпочтительно приземляться.
+// Без ship используется дальность, заданная при создании планировщика.
+// Ответ — capital.RoutePlan: прыжки с расстояниями, расписание с учётом усталости
+// (capital.Timeline), а при указанном ship — профиль корабля и расход топлива.
+func NewCapitalHandler(planner *capital.Planner) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		start := q.Get("start")
+		end := q.Get("end")
+		if start == "" || end == "" {
+			log.Printf("capital api: missing start or end (start=%q end=%q)", start, end)
+			http.Error(w, "missing start or end", http.StatusBadRequest)
+			return
+		}
+		var err error
+		var fatigue float64
+		if v := q.Get("fatigue"); v != "" {
+			if fatigue, err = strconv.ParseFloat(v, 64); err != nil {
+				http.Error(w, "invalid fatigue", http.StatusBadRequest)
+				return
+			}
+		}
+		var opts capital.Options
+		if opts.Optimize, err = capital.ParseOptimize(q.Get("optimize")); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		opts.Avoid = splitList(q.Get("avoid"))
+		opts.Prefer = splitList(q.Get("prefer"))
+		var ship capital.ShipProfile
+		var skills capital.FuelSkills
+		var jdc int
+		if class := q.Get("ship"); class != "" {
+			ship, err = capital.Ship(class)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			for _, p := range []struct {
+				name  string
+				value *int
+				def   int
+			}{{"jdc", &jdc, capital.MaxJDC}, {"jfc", &skills.JFC, capital.MaxSkillLevel}, {"hull", &skills.Hull, capital.MaxSkillLevel}} {
+				*p.value = p.def
+				if v := q.Get(p.name); v != "" {
+					if *p.value, err = strconv.Atoi(v); err != nil {
+						http.Error(w, "invalid "+p.name, http.StatusBadRequest)
+						return
+					}
+				}
+			}
+			if opts.Range, err = ship.Range(jdc); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			opts.Ship = ship
+		} else {
+			for _, name := range []string{"jdc", "race", "jfc", "hull"} {
+				if q.Get(name) != "" {
+					http.Error(w, name+" requires ship", http.StatusBadRequest)
+					return
+				}
+			}
+		}
+		path, err := planner.PlanWithOptions(start, end, opts)
+		if err != nil {
+			log.Printf("capital api: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		timeline, err := capital.Timeline(path, ship, fatigue)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		plan := capital.NewRoutePlan(path)
+		plan.Mode = opts.Optimize
+		plan.Range = opts.Range
+		if plan.Range == 0 {
+			plan.Range = planner.JumpRange()
+		}
+		plan.Timeline = timeline
+		if ship.Class != "" {
+			fuel, err := ship.Fuel(path, q.Get("race"), skills)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			plan.Ship, plan.JDC, plan.Fuel = &ship, &jdc, &fuel
+		}
+		_ = json.NewEncoder(w).Encode(plan)
+	}
+}
+
+// NewShipsHandler возвращает HTTP-обработчик списка классов кораблей (capital.Ships),
+// которые принимает параметр ship.
+func NewShipsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"ships": capital.Ships()})
+	}
+}
+
+// NewHybridHandler возвращает HTTP-обработчик гибридного маршрута (врата и прыжки).
+// Параметры запроса: start и end — названия систем, ship — класс корабля
+// (по умолчанию jf), jdc — уровень Jump Drive Calibration (по умолчанию 5),
+// gateCost и jumpCost — стоимость перехода через врата и прыжка.
+func NewHybridHandler(h *capital.Hybrid) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		start := q.Get("start")
+		end := q.Get("end")
+		if start == "" || end == "" {
+			http.Error(w, "missing start or end", http.StatusBadRequest)
+			return
+		}
+		class := q.Get("ship")
+		if class == "" {
+			class = string(capital.ShipJumpFreighter)
+		}
+		ship, err := capital.Ship(class)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		opts := capital.HybridOptions{Ship: ship}
+		jdc := capital.MaxJDC
+		if v := q.Get("jdc"); v != "" {
+			if jdc, err = strconv.Atoi(v); err != nil {
+				http.Error(w, "invalid jdc", http.StatusBadRequest)
+				return
+			}
+		}
+		if opts.Range, err = ship.Range(jdc); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		for _, p := range []struct {
+			name  string
+			value *float64
+		}{{"gateCost", &opts.GateCost}, {"jumpCost", &opts.JumpCost}} {
+			if v := q.Get(p.name); v != "" {
+				if *p.value, err = strconv.ParseFloat(v, 64); err != nil {
+					http.Error(w, "invalid "+p.name, http.StatusBadRequest)
+					return
+				}
+			}
+		}
+		it, err := h.Plan(r.Context(), start, end, opts)
+		if err != nil {
+			log.Printf("capital api: %v", err)
+			if r.Context().Err() != nil {
+				http.Error(w, err.Error(), http.StatusServiceUnavailable)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"ship": ship, "jdc": jdc, "range": opts.Range, "itinerary": it})
+	}
+}
+
+// NewBridgeHandler возвращает HTTP-обработчик цепочки мостов Black Ops или титана.
+// Параметры запроса: start и end — точка сбора и цель, ship — blackops или titan,
+// jdc — уровень Jump Drive Calibration (по умолчанию 5), mass — масса флота в кг
+// или ships — число кораблей, race, jfc и hull — как в NewCapitalHandler,
+// avoid и prefer — системы через запятую.
+func NewBridgeHandler(planner *capital.Planner) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		start := q.Get("start")
+		end := q.Get("end")
+		if start == "" || end == "" {
+			http.Error(w, "missing start or end", http.StatusBadRequest)
+			return
+		}
+		bridge, err := capital.Bridge(q.Get("ship"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		opts := capital.BridgeOptions{
+			Race:   q.Get("race"),
+			Avoid:  splitList(q.Get("avoid")),
+			Prefer: splitList(q.Get("prefer")),
+		}
+		for _, p := range []struct {
+			name  string
+			value *int
+			def   int
+		}{{"jdc", &opts.JDC, capital.MaxJDC}, {"jfc", &opts.Skills.JFC, capital.MaxSkillLevel}, {"hull", &opts.Skills.Hull, capital.MaxSkillLevel}, {"ships", &opts.Ships, 0}} {
+			*p.value = p.def
+			if v := q.Get(p.name); v != "" {
+				if *p.value, err = strconv.Atoi(v); err != nil {
+					http.Error(w, "invalid "+p.name, http.StatusBadRequest)
+					return
+				}
+			}
+		}
+		if v := q.Get("mass"); v != "" {
+			if opts.Mass, err = strconv.ParseFloat(v, 64); err != nil || opts.Mass < 0 {
+				http.Error(w, "invalid mass", http.StatusBadRequest)
+				return
+			}
+		}
+		plan, err := planner.PlanBridge(start, end, bridge, opts)
+		if err != nil {
+			log.Printf("capital api: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(plan)
+	}
+}
+
+// maxRangeLy — наибольшая дальность ly для поиска систем в пределах прыжка:
+// дальность Jump Freighter и Rorqual с JDC 5.
+const maxRangeLy = 10
+
+// NewRangeHandler возвращает HTTP-обработчик поиска систем в пределах одного прыжка.
+// Параметры запроса: system — название системы, ship и jdc — корабль и уровень
+// Jump Drive Calibration (по умолчанию 5) или ly — дальность в световых годах
+// (не больше maxRangeLy), reverse=true — искать системы, из которых можно прыгнуть
+// в system. ship и ly нельзя указывать вместе, jdc без ship не допускается.
+func NewRangeHandler(planner *capital.Planner) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		name := q.Get("system")
+		if name == "" {
+			http.Error(w, "missing system", http.StatusBadRequest)
+			return
+		}
+		reverse := q.Get("reverse") == "true"
+		if q.Get("ship") != "" && q.Get("ly") != "" {
+			http.Error(w, "ship and ly cannot be combined", http.StatusBadRequest)
+			return
+		}
+		if q.Get("ship") == "" && q.Get("jdc") != "" {
+			http.Error(w, "jdc requires ship", http.StatusBadRequest)
+			return
+		}
+		var ship capital.ShipProfile
+		var jumpRange float64
+		var err error
+		if class := q.Get("ship"); class != "" {
+			if ship, err = capital.Ship(class); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			jdc := capital.MaxJDC
+			if v := q.Get("jdc"); v != "" {
+				if jdc, err = strconv.Atoi(v); err != nil {
+					http.Error(w, "invalid jdc", http.StatusBadRequest)
+					return
+				}
+			}
+			if jumpRange, err = ship.Range(jdc); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		if v := q.Get("ly"); v != "" {
+			// !(... && ...) отсекает и NaN
+			if jumpRange, err = strconv.ParseFloat(v, 64); err != nil || !(jumpRange > 0 && jumpRange <= maxRangeLy) {
+				http.Error(w, fmt.Sprintf("invalid ly (0 < ly <= %d)", maxRangeLy), http.StatusBadRequest)
+				return
+			}
+		}
+		if jumpRange == 0 {
+			http.Error(w, "missing ship or ly", http.StatusBadRequest)
+			return
+		}
+		systems, err := planner.InRange(name, jumpRange, ship, reverse)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"system": name, "range": jumpRange, "reverse": reverse, "systems": systems})
+	}
+}
+
+// splitList разбирает список значений через запятую, пропуская пустые.
+func splitList(s string) []string {
+	var res []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			res = append(res, v)
+		}
+	}
+	return res
+}
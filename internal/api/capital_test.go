@@ -0,0 +1,239 @@
+package api
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tkhamez/eve-route-go/internal/capital"
+	dbstore "github.com/tkhamez/eve-route-go/internal/dbstore"
+	"github.com/tkhamez/eve-route-go/internal/graph"
+	routepkg "github.com/tkhamez/eve-route-go/internal/route"
+)
+
+func TestNewCapitalHandler(t *testing.T) {
+	planner, err := capital.NewPlanner(dbstore.NewMemory(nil, nil, capital.DefaultSystems()), 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	h := NewCapitalHandler(planner)
+
+	rr := httptest.NewRecorder()
+	h(rr, httptest.NewRequest(http.MethodGet, "/api/capital?start=Maila&end=Todifrauan&ship=jf&jdc=5&fatigue=30&race=amarr&jfc=4&optimize=distance", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body)
+	}
+	var plan capital.RoutePlan
+	if err := json.NewDecoder(rr.Body).Decode(&plan); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if plan.Ship == nil || plan.Ship.Class != capital.ShipJumpFreighter || plan.JDC == nil || *plan.JDC != 5 || plan.Range != 10 {
+		t.Fatalf("unexpected plan: %+v", plan)
+	}
+	if plan.Mode != capital.OptimizeDistance || plan.Jumps < 1 || len(plan.Legs) != plan.Jumps || len(plan.Systems) != plan.Jumps+1 {
+		t.Fatalf("unexpected plan: %+v", plan)
+	}
+	last := plan.Legs[len(plan.Legs)-1]
+	if last.To.Name != "Todifrauan" || last.To.Region != "Metropolis" || math.Abs(last.Cumulative-plan.Distance) > 1e-9 {
+		t.Fatalf("unexpected last leg: %+v", last)
+	}
+	if len(plan.Timeline) != plan.Jumps || plan.Timeline[0].FatigueBefore != 30 {
+		t.Fatalf("unexpected timeline: %+v", plan.Timeline)
+	}
+	// 10000 × (1 − 0,4) × (1 − 0,5) = 3000 изотопов на световой год
+	if plan.Fuel == nil || plan.Fuel.Isotope != "Oxygen Isotopes" || plan.Fuel.PerLY != 3000 || len(plan.Fuel.Legs) != plan.Jumps || plan.Fuel.Total <= 0 {
+		t.Fatalf("unexpected fuel: %+v", plan.Fuel)
+	}
+
+	// без корабля используется дальность планировщика, топливо не считается
+	rr = httptest.NewRecorder()
+	h(rr, httptest.NewRequest(http.MethodGet, "/api/capital?start=Maila&end=Todifrauan", nil))
+	var raw map[string]any
+	if err := json.NewDecoder(rr.Body).Decode(&raw); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if raw["range"] != 5.0 || raw["mode"] != "jumps" || raw["ship"] != nil || raw["fuel"] != nil || raw["route"] != nil {
+		t.Fatalf("unexpected response: %v", raw)
+	}
+
+	for _, url := range []string{
+		"/api/capital?start=Maila",
+		"/api/capital?start=Maila&end=Todifrauan&ship=shuttle",
+		"/api/capital?start=Maila&end=Todifrauan&ship=titan&jdc=9",
+		"/api/capital?start=Maila&end=Todifrauan&ship=titan&jdc=x",
+		"/api/capital?start=Maila&end=Todifrauan&jdc=4",
+		"/api/capital?start=Maila&end=Todifrauan&ship=titan&jdc=0",
+		"/api/capital?start=Maila&end=Todifrauan&fatigue=x",
+		"/api/capital?start=Maila&end=Todifrauan&fatigue=-5",
+		"/api/capital?start=Maila&end=Todifrauan&race=amarr",
+		"/api/capital?start=Maila&end=Todifrauan&ship=jf&race=jove",
+		"/api/capital?start=Maila&end=Todifrauan&ship=jf&jfc=6",
+		"/api/capital?start=Maila&end=Todifrauan&ship=jf&hull=x",
+		"/api/capital?start=Maila&end=Todifrauan&optimize=fuel",
+		"/api/capital?start=Maila&end=Todifrauan&avoid=Nowhere",
+		"/api/capital?start=Maila&end=Todifrauan&avoid=Eystur,Isendeldik",
+	} {
+		rr = httptest.NewRecorder()
+		h(rr, httptest.NewRequest(http.MethodGet, url, nil))
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", url, rr.Code)
+		}
+	}
+}
+
+func TestNewShipsHandler(t *testing.T) {
+	rr := httptest.NewRecorder()
+	NewShipsHandler()(rr, httptest.NewRequest(http.MethodGet, "/api/capital/ships", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	var resp struct {
+		Ships []capital.ShipProfile `json:"ships"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Ships) != len(capital.Ships()) || resp.Ships[0].Class != capital.ShipBlackOps || resp.Ships[0].Name == "" {
+		t.Fatalf("unexpected ships: %+v", resp.Ships)
+	}
+}
+
+func TestNewHybridHandler(t *testing.T) {
+	g := graph.Graph{
+		Systems: []graph.System{
+			{ID: 1, Name: "Jita", Security: 0.95, RegionID: 1, X: 1e16},
+			{ID: 2, Name: "Perimeter", Security: 0.9, RegionID: 1, X: 2e16},
+		},
+		Connections: [][2]int{{1, 2}},
+		Regions:     map[int]string{1: "The Forge"},
+	}
+	store := dbstore.NewMemory(nil, nil, capital.SystemsFromGraph(g))
+	r, err := routepkg.NewRouteFromGraph(g, store, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	planner, err := capital.NewPlanner(store, 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	h := NewHybridHandler(capital.NewHybrid(r, planner))
+
+	rr := httptest.NewRecorder()
+	h(rr, httptest.NewRequest(http.MethodGet, "/api/capital/hybrid?start=Jita&end=Perimeter", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body)
+	}
+	var resp struct {
+		Ship      capital.ShipProfile `json:"ship"`
+		Itinerary capital.Itinerary   `json:"itinerary"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Ship.Class != capital.ShipJumpFreighter || len(resp.Itinerary.Legs) != 1 || resp.Itinerary.Legs[0].Type != capital.LegGate {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+
+	for _, url := range []string{
+		"/api/capital/hybrid?start=Jita",
+		"/api/capital/hybrid?start=Jita&end=Perimeter&ship=shuttle",
+		"/api/capital/hybrid?start=Jita&end=Perimeter&jdc=7",
+		"/api/capital/hybrid?start=Jita&end=Perimeter&gateCost=x",
+		"/api/capital/hybrid?start=Jita&end=Perimeter&jumpCost=-1",
+		"/api/capital/hybrid?start=Jita&end=Perimeter&ship=carrier",
+	} {
+		rr = httptest.NewRecorder()
+		h(rr, httptest.NewRequest(http.MethodGet, url, nil))
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", url, rr.Code)
+		}
+	}
+}
+
+func TestNewBridgeHandler(t *testing.T) {
+	planner, err := capital.NewPlanner(dbstore.NewMemory(nil, nil, capital.DefaultSystems()), 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	h := NewBridgeHandler(planner)
+
+	rr := httptest.NewRecorder()
+	h(rr, httptest.NewRequest(http.MethodGet, "/api/capital/bridge?start=Maila&end=Todifrauan&ship=blackops&ships=20&race=minmatar", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body)
+	}
+	var plan capital.BridgePlan
+	if err := json.NewDecoder(rr.Body).Decode(&plan); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if plan.Bridge.Cyno != capital.CynoCovert || plan.Range != 8 || len(plan.Hops) == 0 || plan.TotalFuel <= 0 || plan.Isotope != "Hydrogen Isotopes" {
+		t.Fatalf("unexpected plan: %+v", plan)
+	}
+
+	for _, url := range []string{
+		"/api/capital/bridge?start=Maila",
+		"/api/capital/bridge?start=Maila&end=Todifrauan&ship=carrier&ships=1",
+		"/api/capital/bridge?start=Maila&end=Todifrauan&ship=titan",
+		"/api/capital/bridge?start=Maila&end=Todifrauan&ship=titan&mass=x",
+		"/api/capital/bridge?start=Maila&end=Todifrauan&ship=titan&ships=1&jdc=6",
+	} {
+		rr = httptest.NewRecorder()
+		h(rr, httptest.NewRequest(http.MethodGet, url, nil))
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", url, rr.Code)
+		}
+	}
+}
+
+func TestNewRangeHandler(t *testing.T) {
+	planner, err := capital.NewPlanner(dbstore.NewMemory(nil, nil, capital.DefaultSystems()), 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	h := NewRangeHandler(planner)
+
+	rr := httptest.NewRecorder()
+	h(rr, httptest.NewRequest(http.MethodGet, "/api/capital/range?system=Maila&ship=jf&reverse=true", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body)
+	}
+	var resp struct {
+		Range   float64             `json:"range"`
+		Reverse bool                `json:"reverse"`
+		Systems []capital.Reachable `json:"systems"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Range != 10 || !resp.Reverse || len(resp.Systems) == 0 || resp.Systems[0].Region == "" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	rr = httptest.NewRecorder()
+	h(rr, httptest.NewRequest(http.MethodGet, "/api/capital/range?system=Maila&ly=10", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200 for maximum ly, got %d: %s", rr.Code, rr.Body)
+	}
+
+	for _, url := range []string{
+		"/api/capital/range",
+		"/api/capital/range?system=Maila",
+		"/api/capital/range?system=Maila&ly=x",
+		"/api/capital/range?system=Maila&ly=-1",
+		"/api/capital/range?system=Maila&ly=0",
+		"/api/capital/range?system=Maila&ly=1e9",
+		"/api/capital/range?system=Maila&ly=NaN",
+		"/api/capital/range?system=Maila&ly=5&jdc=3",
+		"/api/capital/range?system=Maila&jdc=3",
+		"/api/capital/range?system=Maila&ship=jf&ly=5",
+		"/api/capital/range?system=Nowhere&ly=5",
+		"/api/capital/range?system=Maila&ship=shuttle",
+	} {
+		rr = httptest.NewRecorder()
+		h(rr, httptest.NewRequest(http.MethodGet, url, nil))
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", url, rr.Code)
+		}
+	}
+}
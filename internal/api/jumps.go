@@ -0,0 +1,57 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/tkhamez/eve-route-go/internal/jumps"
+)
+
+// maxJumpsSystems ограничивает количество систем в каждом списке запроса.
+const maxJumpsSystems = 100
+
+// NewJumpsHandler возвращает HTTP-обработчик подсчёта прыжков по сети соединений.
+// Параметры запроса: from и to — системы через запятую. Ответ содержит матрицу
+// jumps (-1 — система недостижима), а для одной пары систем — ещё и путь path.
+func NewJumpsHandler(network *jumps.Network) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		from := splitList(q.Get("from"))
+		to := splitList(q.Get("to"))
+		if len(from) == 0 || len(to) == 0 {
+			http.Error(w, "missing from or to", http.StatusBadRequest)
+			return
+		}
+		if len(from) > maxJumpsSystems || len(to) > maxJumpsSystems {
+			http.Error(w, fmt.Sprintf("too many systems (max %d)", maxJumpsSystems), http.StatusBadRequest)
+			return
+		}
+		c := network.Calculator()
+		resp := map[string]any{"from": from, "to": to}
+		// для одной пары число прыжков — длина пути, отдельная матрица не нужна
+		if len(from) == 1 && len(to) == 1 {
+			path, err := c.Path(from[0], to[0])
+			switch {
+			case errors.Is(err, jumps.ErrNotReachable):
+				resp["jumps"] = [][]int{{-1}}
+			case err != nil:
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			default:
+				resp["jumps"] = [][]int{{len(path)}}
+				resp["path"] = path
+			}
+			_ = json.NewEncoder(w).Encode(resp)
+			return
+		}
+		matrix, err := c.Matrix(from, to)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		resp["jumps"] = matrix
+		_ = json.NewEncoder(w).Encode(resp)
+	}
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	dbstore "github.com/tkhamez/eve-route-go/internal/dbstore"
+	"github.com/tkhamez/eve-route-go/internal/graph"
+	"github.com/tkhamez/eve-route-go/internal/jumps"
+	routepkg "github.com/tkhamez/eve-route-go/internal/route"
+)
+
+func TestNewJumpsHandler(t *testing.T) {
+	g := graph.Graph{
+		Systems: []graph.System{
+			{ID: 1, Name: "A", RegionID: 1},
+			{ID: 2, Name: "B", RegionID: 1},
+			{ID: 3, Name: "C", RegionID: 1},
+			{ID: 4, Name: "D", RegionID: 1},
+		},
+		Connections: [][2]int{{1, 2}, {2, 3}},
+		Regions:     map[int]string{1: "R"},
+	}
+	store := dbstore.NewMemory([]dbstore.Ansiblex{
+		{ID: 1, Name: graph.AnsiblexName("A", "C", "JB"), SolarSystemID: 1},
+		{ID: 2, Name: graph.AnsiblexName("C", "A", "JB"), SolarSystemID: 3},
+	}, nil, nil)
+	r, err := routepkg.NewRouteFromGraph(g, store, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	h := NewJumpsHandler(jumps.NewNetwork(r))
+
+	rr := httptest.NewRecorder()
+	h(rr, httptest.NewRequest(http.MethodGet, "/api/jumps?from=A&to=C", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body)
+	}
+	var resp struct {
+		Jumps [][]int     `json:"jumps"`
+		Path  []jumps.Hop `json:"path"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Jumps[0][0] != 1 || len(resp.Path) != 1 || resp.Path[0].Type != "Ansiblex" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+
+	rr = httptest.NewRecorder()
+	h(rr, httptest.NewRequest(http.MethodGet, "/api/jumps?from=A,B&to=B,C", nil))
+	resp.Path = nil
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Jumps) != 2 || resp.Jumps[1][0] != 0 || resp.Path != nil {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+
+	rr = httptest.NewRecorder()
+	h(rr, httptest.NewRequest(http.MethodGet, "/api/jumps?from=A&to=D", nil))
+	resp.Path = nil
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if rr.Code != http.StatusOK || resp.Jumps[0][0] != -1 || resp.Path != nil {
+		t.Fatalf("expected unreachable system, got %d %+v", rr.Code, resp)
+	}
+
+	for _, url := range []string{
+		"/api/jumps?from=A",
+		"/api/jumps?from=A&to=Nowhere",
+		"/api/jumps?from=A&to=" + strings.Repeat("B,", maxJumpsSystems+1),
+	} {
+		rr = httptest.NewRecorder()
+		h(rr, httptest.NewRequest(http.MethodGet, url, nil))
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", url, rr.Code)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"strconv"
+	"strings"
+
+	routepkg "github.com/tkhamez/eve-route-go/internal/route"
+)
+
+// MapConnectionsSource возвращает соединения для карты; реализуется
+// routepkg.Route и routepkg.Cache.
+type MapConnectionsSource interface {
+	MapConnections() []routepkg.MapConnection
+}
+
+// NewMapHandler возвращает HTTP-обработчик данных карты: все Ansiblex и временные
+// соединения с ID, названиями и регионами систем, типом, временем закрытия и
+// положением систем на карте. Параметр region — регионы (ID или названия) через
+// запятую; соединение выводится, если хотя бы одна из систем лежит в одном из них.
+// Ответ содержит ETag; при совпадении с If-None-Match возвращается 304.
+func NewMapHandler(src MapConnectionsSource) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		regions := splitList(r.URL.Query().Get("region"))
+		connections := []routepkg.MapConnection{}
+		for _, c := range src.MapConnections() {
+			if len(regions) == 0 || inRegions(c.System1, regions) || inRegions(c.System2, regions) {
+				connections = append(connections, c)
+			}
+		}
+		writeWithETag(w, r, map[string]any{"connections": connections})
+	}
+}
+
+// NewMapConnectionsHandler возвращает соединения для карты в формате, который
+// ожидает фронтенд (ResponseMapConnections): пары названий систем отдельно
+// для Ansiblex и временных соединений.
+func NewMapConnectionsHandler(src MapConnectionsSource) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		type pair struct {
+			System1 string `json:"system1"`
+			System2 string `json:"system2"`
+		}
+		ansiblexes, temporary := []pair{}, []pair{}
+		for _, c := range src.MapConnections() {
+			p := pair{System1: c.System1.Name, System2: c.System2.Name}
+			if c.Type == routepkg.TypeAnsiblex {
+				ansiblexes = append(ansiblexes, p)
+			} else {
+				temporary = append(temporary, p)
+			}
+		}
+		writeWithETag(w, r, map[string]any{"code": nil, "ansiblexes": ansiblexes, "temporary": temporary})
+	}
+}
+
+// inRegions сообщает, лежит ли система в одном из регионов (ID или название).
+func inRegions(s routepkg.MapSystem, regions []string) bool {
+	for _, region := range regions {
+		if id, err := strconv.Atoi(region); err == nil && id == s.RegionID || strings.EqualFold(region, s.Region) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeWithETag кодирует v в JSON и отправляет его с ETag, вычисленным по телу
+// ответа; если клиент прислал тот же ETag в If-None-Match, тело не отправляется.
+func writeWithETag(w http.ResponseWriter, r *http.Request, v any) {
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h := fnv.New64a()
+	_, _ = h.Write(body.Bytes())
+	etag := fmt.Sprintf(`"%x"`, h.Sum64())
+	w.Header().Set("ETag", etag)
+	// данные меняются вместе с соединениями, поэтому клиент должен проверять ETag
+	w.Header().Set("Cache-Control", "no-cache")
+	for _, tag := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+		if tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/"); tag == etag || tag == "*" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body.Bytes())
+}
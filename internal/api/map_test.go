@@ -0,0 +1,80 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	routepkg "github.com/tkhamez/eve-route-go/internal/route"
+)
+
+// staticMap возвращает заданный список соединений.
+type staticMap []routepkg.MapConnection
+
+func (m staticMap) MapConnections() []routepkg.MapConnection { return m }
+
+func TestNewMapHandler(t *testing.T) {
+	src := staticMap{
+		{Type: routepkg.TypeAnsiblex, System1: routepkg.MapSystem{ID: 1, Name: "A", RegionID: 10, Region: "North"}, System2: routepkg.MapSystem{ID: 2, Name: "B", RegionID: 10, Region: "North"}},
+		{Type: routepkg.TypeTemporary, System1: routepkg.MapSystem{ID: 2, Name: "B", RegionID: 10, Region: "North"}, System2: routepkg.MapSystem{ID: 3, Name: "C", RegionID: 20, Region: "South"}},
+		{Type: routepkg.TypeAnsiblex, System1: routepkg.MapSystem{ID: 3, Name: "C", RegionID: 20, Region: "South"}, System2: routepkg.MapSystem{ID: 4, Name: "D", RegionID: 20, Region: "South"}},
+	}
+	h := NewMapHandler(src)
+	get := func(url, etag string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		rr := httptest.NewRecorder()
+		h(rr, req)
+		return rr
+	}
+	count := func(rr *httptest.ResponseRecorder) int {
+		var resp struct {
+			Connections []routepkg.MapConnection `json:"connections"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return len(resp.Connections)
+	}
+
+	rr := get("/api/route/map", "")
+	etag := rr.Header().Get("ETag")
+	if rr.Code != http.StatusOK || etag == "" || count(rr) != 3 {
+		t.Fatalf("unexpected response: %d %q", rr.Code, etag)
+	}
+	if n := count(get("/api/route/map?region=north", "")); n != 2 {
+		t.Errorf("expected 2 connections touching North, got %d", n)
+	}
+	if n := count(get("/api/route/map?region=20", "")); n != 2 {
+		t.Errorf("expected 2 connections touching region 20, got %d", n)
+	}
+	if rr := get("/api/route/map", etag); rr.Code != http.StatusNotModified || rr.Body.Len() != 0 {
+		t.Errorf("expected 304 without body, got %d", rr.Code)
+	}
+	if rr := get("/api/route/map?region=North", etag); rr.Code != http.StatusOK {
+		t.Errorf("filtered response must have another ETag, got %d", rr.Code)
+	}
+}
+
+func TestNewMapConnectionsHandler(t *testing.T) {
+	src := staticMap{
+		{Type: routepkg.TypeAnsiblex, System1: routepkg.MapSystem{Name: "A"}, System2: routepkg.MapSystem{Name: "B"}},
+		{Type: routepkg.TypeTemporary, System1: routepkg.MapSystem{Name: "B"}, System2: routepkg.MapSystem{Name: "C"}},
+	}
+	rr := httptest.NewRecorder()
+	NewMapConnectionsHandler(src)(rr, httptest.NewRequest(http.MethodGet, "/api/route/map-connections", nil))
+	var resp struct {
+		Code       *string             `json:"code"`
+		Ansiblexes []map[string]string `json:"ansiblexes"`
+		Temporary  []map[string]string `json:"temporary"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Code != nil || len(resp.Ansiblexes) != 1 || resp.Ansiblexes[0]["system2"] != "B" || len(resp.Temporary) != 1 || resp.Temporary[0]["system1"] != "B" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	routepkg "github.com/tkhamez/eve-route-go/internal/route"
+)
+
+// defaultRallyLimit — количество кандидатов по умолчанию.
+const defaultRallyLimit = 5
+
+// NewRallyHandler возвращает HTTP-обработчик поиска точки сбора флота.
+// Параметры запроса: members — системы участников через запятую,
+// mode — "max" или "total", limit — количество кандидатов.
+// Поиск идёт по текущему маршрутизатору src.
+func NewRallyHandler(src routepkg.Source) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		q := req.URL.Query()
+		members := splitList(q.Get("members"))
+		if len(members) == 0 {
+			http.Error(w, "missing members", http.StatusBadRequest)
+			return
+		}
+		mode := routepkg.RallyMode(q.Get("mode"))
+		if mode == "" {
+			mode = routepkg.RallyMinMax
+		}
+		limit := defaultRallyLimit
+		if l := q.Get("limit"); l != "" {
+			n, err := strconv.Atoi(l)
+			if err != nil || n <= 0 {
+				http.Error(w, "invalid limit", http.StatusBadRequest)
+				return
+			}
+			limit = n
+		}
+		candidates, err := src.Route().RallyPoints(req.Context(), members, mode, limit)
+		if err != nil {
+			if req.Context().Err() != nil {
+				http.Error(w, err.Error(), http.StatusServiceUnavailable)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if len(candidates) == 0 {
+			http.NotFound(w, req)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"candidates": candidates})
+	}
+}
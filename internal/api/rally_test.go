@@ -0,0 +1,44 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	dbstore "github.com/tkhamez/eve-route-go/internal/dbstore"
+	routepkg "github.com/tkhamez/eve-route-go/internal/route"
+)
+
+func TestNewRallyHandler(t *testing.T) {
+	planner, err := routepkg.NewRoute(dbstore.NewMemory(nil, nil, nil), nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	router := mux.NewRouter()
+	router.HandleFunc("/api/route/rally", NewRallyHandler(planner)).Methods("GET")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/route/rally?members=Alpha,Beta&limit=1", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	var resp struct {
+		Candidates []routepkg.RallyCandidate `json:"candidates"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Candidates) != 1 || len(resp.Candidates[0].Routes) != 2 {
+		t.Fatalf("unexpected candidates: %+v", resp.Candidates)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/route/rally", nil)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rr.Code)
+	}
+}
@@ -1,15 +1,30 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"log"
 	"net/http"
 
 	"github.com/gorilla/mux"
 	routepkg "github.com/tkhamez/eve-route-go/internal/route"
 )
 
+// RouteFinder ищет маршруты; реализуется routepkg.Route и routepkg.Cache.
+type RouteFinder interface {
+	FindWithOptions(ctx context.Context, from, to string, opts routepkg.FindOptions) ([][]routepkg.Waypoint, error)
+}
+
+// FindOptionsSource возвращает параметры поиска для запроса; реализуется UserRoute.
+type FindOptionsSource interface {
+	FindOptions(req *http.Request) (routepkg.FindOptions, error)
+}
+
 // NewRouteHandler возвращает HTTP-обработчик, строящий маршрут между системами.
-func NewRouteHandler(r *routepkg.Route) http.HandlerFunc {
+// Параметры поиска берутся из opts; nil означает поиск без ограничений.
+// Если параметры не удалось загрузить, маршрут ищется без ограничений.
+// Поиск прерывается, когда отменяется контекст запроса.
+func NewRouteHandler(r RouteFinder, opts FindOptionsSource) http.HandlerFunc {
 	return func(w http.ResponseWriter, req *http.Request) {
 		vars := mux.Vars(req)
 		from := vars["from"]
@@ -18,7 +33,21 @@ func NewRouteHandler(r *routepkg.Route) http.HandlerFunc {
 			http.Error(w, "missing from or to", http.StatusBadRequest)
 			return
 		}
-		paths := r.Find(from, to)
+		var findOpts routepkg.FindOptions
+		if opts != nil {
+			var err error
+			// без настроек персонажа маршрут всё равно полезен: ищем без ограничений
+			if findOpts, err = opts.FindOptions(req); err != nil {
+				log.Printf("route api: cannot load route settings, searching without them: %v", err)
+				findOpts = routepkg.FindOptions{}
+			}
+		}
+		paths, err := r.FindWithOptions(req.Context(), from, to, findOpts)
+		if err != nil {
+			log.Printf("route api: %s -> %s: %v", from, to, err)
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
 		if len(paths) == 0 {
 			http.NotFound(w, req)
 			return
@@ -26,3 +55,11 @@ func NewRouteHandler(r *routepkg.Route) http.HandlerFunc {
 		_ = json.NewEncoder(w).Encode(map[string]any{"routes": paths})
 	}
 }
+
+// NewCacheStatsHandler возвращает счётчики кэша маршрутов для мониторинга.
+func NewCacheStatsHandler(c *routepkg.Cache) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(c.Stats())
+	}
+}
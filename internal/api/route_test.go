@@ -2,6 +2,7 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -18,7 +19,7 @@ func TestNewRouteHandler(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 	router := mux.NewRouter()
-	router.HandleFunc("/api/route/{from}/{to}", NewRouteHandler(planner)).Methods("GET")
+	router.HandleFunc("/api/route/{from}/{to}", NewRouteHandler(planner, nil)).Methods("GET")
 
 	req := httptest.NewRequest(http.MethodGet, "/api/route/Alpha/Gamma", nil)
 	rr := httptest.NewRecorder()
@@ -53,7 +54,7 @@ func TestNewRouteHandlerNotFound(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 	router := mux.NewRouter()
-	router.HandleFunc("/api/route/{from}/{to}", NewRouteHandler(planner)).Methods("GET")
+	router.HandleFunc("/api/route/{from}/{to}", NewRouteHandler(planner, nil)).Methods("GET")
 
 	req := httptest.NewRequest(http.MethodGet, "/api/route/Unknown/Gamma", nil)
 	rr := httptest.NewRecorder()
@@ -63,3 +64,55 @@ func TestNewRouteHandlerNotFound(t *testing.T) {
 		t.Fatalf("expected 404, got %d", rr.Code)
 	}
 }
+
+func TestNewRouteHandlerCached(t *testing.T) {
+	store := dbstore.NewMemory(nil, nil, nil)
+	planner, err := routepkg.NewRoute(store, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cache := routepkg.NewCache(planner, 10)
+	router := mux.NewRouter()
+	router.HandleFunc("/api/route/cache-stats", NewCacheStatsHandler(cache)).Methods("GET")
+	router.HandleFunc("/api/route/{from}/{to}", NewRouteHandler(cache, nil)).Methods("GET")
+
+	for i := 0; i < 2; i++ {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/route/Alpha/Gamma", nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rr.Code)
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/route/cache-stats", nil))
+	var stats routepkg.CacheStats
+	if err := json.NewDecoder(rr.Body).Decode(&stats); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if stats.Hits != 1 || stats.Misses != 1 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+// failingOptions имитирует недоступное хранилище настроек маршрута.
+type failingOptions struct{}
+
+func (failingOptions) FindOptions(*http.Request) (routepkg.FindOptions, error) {
+	return routepkg.FindOptions{}, errors.New("storage unavailable")
+}
+
+func TestNewRouteHandlerOptionsError(t *testing.T) {
+	planner, err := routepkg.NewRoute(dbstore.NewMemory(nil, nil, nil), nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	router := mux.NewRouter()
+	router.HandleFunc("/api/route/{from}/{to}", NewRouteHandler(planner, failingOptions{})).Methods("GET")
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/route/Alpha/Gamma", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected unfiltered route with status 200, got %d", rr.Code)
+	}
+}
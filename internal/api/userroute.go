@@ -0,0 +1,203 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	dbstore "github.com/tkhamez/eve-route-go/internal/dbstore"
+	"github.com/tkhamez/eve-route-go/internal/graph"
+	routepkg "github.com/tkhamez/eve-route-go/internal/route"
+)
+
+// CharacterSessions определяет персонажа по сессии запроса; реализуется auth.Manager.
+type CharacterSessions interface {
+	CharacterID(r *http.Request) (int, bool)
+}
+
+// UserRoute хранит настройки маршрута персонажей — системы, которые нужно обходить,
+// и удалённые соединения — и применяет их к поиску маршрута этого персонажа.
+type UserRoute struct {
+	sessions CharacterSessions
+	store    dbstore.UserStore
+	helper   *graph.Helper
+}
+
+// NewUserRoute создаёт обработчики настроек маршрута; системы ищутся в графе g.
+func NewUserRoute(sessions CharacterSessions, store dbstore.UserStore, g graph.Graph) *UserRoute {
+	return &UserRoute{sessions: sessions, store: store, helper: graph.NewHelper(g)}
+}
+
+// Register регистрирует маршруты API. Каждый обработчик доступен по короткому пути
+// и по пути, который использует фронтенд.
+func (u *UserRoute) Register(r *mux.Router) {
+	for _, p := range []struct {
+		method  string
+		paths   []string
+		handler http.HandlerFunc
+	}{
+		{"POST", []string{"/api/route/avoid/{system}", "/api/route/avoid-system/{system}"}, u.avoid},
+		{"GET", []string{"/api/route/avoided", "/api/route/avoided-systems"}, u.avoided},
+		{"POST", []string{"/api/route/remove/{from}/{to}", "/api/route/remove-connection/{from}/{to}"}, u.remove},
+		{"GET", []string{"/api/route/removed", "/api/route/removed-connections"}, u.removed},
+		{"POST", []string{"/api/route/reset", "/api/route/reset-avoided-system-and-removed-connection"}, u.reset},
+	} {
+		for _, path := range p.paths {
+			r.Handle(path, u.character(p.handler)).Methods(p.method)
+		}
+	}
+}
+
+// FindOptions возвращает параметры поиска маршрута для персонажа запроса;
+// без входа в систему параметры пусты.
+func (u *UserRoute) FindOptions(r *http.Request) (routepkg.FindOptions, error) {
+	var opts routepkg.FindOptions
+	id, ok := u.sessions.CharacterID(r)
+	if !ok {
+		return opts, nil
+	}
+	avoided, err := u.store.AvoidedSystems(r.Context(), id)
+	if err != nil {
+		return opts, err
+	}
+	removed, err := u.store.RemovedConnections(r.Context(), id)
+	if err != nil {
+		return opts, err
+	}
+	opts.AvoidedSystems = avoided
+	opts.RemovedConnections = u.connectedSystems(removed)
+	return opts, nil
+}
+
+// character пропускает только запросы персонажа, вошедшего в систему.
+func (u *UserRoute) character(next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := u.sessions.CharacterID(r); !ok {
+			http.Error(w, "not logged in", http.StatusForbidden)
+			return
+		}
+		next(w, r)
+	})
+}
+
+func (u *UserRoute) avoid(w http.ResponseWriter, r *http.Request) {
+	id, _ := u.sessions.CharacterID(r)
+	s, err := u.system(mux.Vars(r)["system"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := u.store.AddAvoidedSystem(r.Context(), id, s.ID); err != nil {
+		u.fail(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (u *UserRoute) avoided(w http.ResponseWriter, r *http.Request) {
+	id, _ := u.sessions.CharacterID(r)
+	ids, err := u.store.AvoidedSystems(r.Context(), id)
+	if err != nil {
+		u.fail(w, err)
+		return
+	}
+	type system struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	systems := []system{}
+	for _, sid := range ids {
+		if s := u.helper.FindSystem(sid); s != nil {
+			systems = append(systems, system{ID: s.ID, Name: s.Name})
+		}
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{"systems": systems})
+}
+
+func (u *UserRoute) remove(w http.ResponseWriter, r *http.Request) {
+	id, _ := u.sessions.CharacterID(r)
+	vars := mux.Vars(r)
+	from, err := u.system(vars["from"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	to, err := u.system(vars["to"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if from.ID == to.ID {
+		http.Error(w, "connection needs two different systems", http.StatusBadRequest)
+		return
+	}
+	// соединение двунаправленное, пара хранится в порядке возрастания ID
+	c := dbstore.RemovedConnection{System1ID: min(from.ID, to.ID), System2ID: max(from.ID, to.ID)}
+	if err := u.store.AddRemovedConnection(r.Context(), id, c); err != nil {
+		u.fail(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (u *UserRoute) removed(w http.ResponseWriter, r *http.Request) {
+	id, _ := u.sessions.CharacterID(r)
+	removed, err := u.store.RemovedConnections(r.Context(), id)
+	if err != nil {
+		u.fail(w, err)
+		return
+	}
+	type connection struct {
+		System1 string `json:"system1"`
+		System2 string `json:"system2"`
+	}
+	connections := []connection{}
+	for _, c := range u.connectedSystems(removed) {
+		connections = append(connections, connection{System1: c.System1, System2: c.System2})
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{"connections": connections})
+}
+
+func (u *UserRoute) reset(w http.ResponseWriter, r *http.Request) {
+	id, _ := u.sessions.CharacterID(r)
+	if err := u.store.ResetRoute(r.Context(), id); err != nil {
+		u.fail(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// system находит систему по ID или названию.
+func (u *UserRoute) system(v string) (*graph.System, error) {
+	var s *graph.System
+	if id, err := strconv.Atoi(v); err == nil {
+		s = u.helper.FindSystem(id)
+	} else {
+		s = u.helper.FindSystemByName(v)
+	}
+	if s == nil {
+		return nil, fmt.Errorf("system %q not found", v)
+	}
+	return s, nil
+}
+
+// connectedSystems переводит сохранённые соединения в пары названий систем,
+// пропуская системы, которых нет в графе.
+func (u *UserRoute) connectedSystems(removed []dbstore.RemovedConnection) []routepkg.ConnectedSystems {
+	var res []routepkg.ConnectedSystems
+	for _, c := range removed {
+		s1, s2 := u.helper.FindSystem(c.System1ID), u.helper.FindSystem(c.System2ID)
+		if s1 != nil && s2 != nil {
+			res = append(res, routepkg.ConnectedSystems{System1: s1.Name, System2: s2.Name})
+		}
+	}
+	return res
+}
+
+func (u *UserRoute) fail(w http.ResponseWriter, err error) {
+	log.Printf("user route api: %v", err)
+	http.Error(w, "storage error", http.StatusInternalServerError)
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gorilla/mux"
+	dbstore "github.com/tkhamez/eve-route-go/internal/dbstore"
+	"github.com/tkhamez/eve-route-go/internal/graph"
+	routepkg "github.com/tkhamez/eve-route-go/internal/route"
+)
+
+// headerSessions берёт ID персонажа из заголовка X-Character.
+type headerSessions struct{}
+
+func (headerSessions) CharacterID(r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(r.Header.Get("X-Character"))
+	return id, err == nil
+}
+
+func TestUserRoute(t *testing.T) {
+	store := dbstore.NewMemory(nil, nil, nil)
+	planner, err := routepkg.NewRoute(store, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	u := NewUserRoute(headerSessions{}, store, graph.DefaultGraph())
+	router := mux.NewRouter()
+	u.Register(router)
+	router.HandleFunc("/api/route/{from}/{to}", NewRouteHandler(planner, u)).Methods("GET")
+
+	do := func(method, url, character string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, nil)
+		if character != "" {
+			req.Header.Set("X-Character", character)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+	routeLen := func(character string) int {
+		rr := do(http.MethodGet, "/api/route/Alpha/Gamma", character)
+		var resp struct {
+			Routes [][]routepkg.Waypoint `json:"routes"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return len(resp.Routes[0])
+	}
+
+	if rr := do(http.MethodPost, "/api/route/avoid/2", ""); rr.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 without session, got %d", rr.Code)
+	}
+	if rr := do(http.MethodPost, "/api/route/avoid-system/Nowhere", "7"); rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown system, got %d", rr.Code)
+	}
+	if rr := do(http.MethodPost, "/api/route/remove/Alpha/Alpha", "7"); rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for same system, got %d", rr.Code)
+	}
+	if rr := do(http.MethodPost, "/api/route/remove-connection/Gamma/Alpha", "7"); rr.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", rr.Code)
+	}
+
+	// прямое соединение удалено, маршрут идёт через Beta
+	if n := routeLen("7"); n != 3 {
+		t.Fatalf("expected route via Beta, got %d waypoints", n)
+	}
+	if n := routeLen("8"); n != 2 {
+		t.Fatalf("other character should get direct route, got %d waypoints", n)
+	}
+
+	var removed struct {
+		Connections []map[string]string `json:"connections"`
+	}
+	_ = json.NewDecoder(do(http.MethodGet, "/api/route/removed-connections", "7").Body).Decode(&removed)
+	if len(removed.Connections) != 1 || removed.Connections[0]["system1"] != "Alpha" || removed.Connections[0]["system2"] != "Gamma" {
+		t.Fatalf("unexpected removed connections: %+v", removed)
+	}
+
+	if rr := do(http.MethodPost, "/api/route/avoid/2", "7"); rr.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", rr.Code)
+	}
+	var avoided struct {
+		Systems []struct {
+			ID   int    `json:"id"`
+			Name string `json:"name"`
+		} `json:"systems"`
+	}
+	_ = json.NewDecoder(do(http.MethodGet, "/api/route/avoided", "7").Body).Decode(&avoided)
+	if len(avoided.Systems) != 1 || avoided.Systems[0].Name != "Beta" {
+		t.Fatalf("unexpected avoided systems: %+v", avoided)
+	}
+	if rr := do(http.MethodGet, "/api/route/Alpha/Gamma", "7"); rr.Code != http.StatusNotFound {
+		t.Fatalf("expected no route, got %d", rr.Code)
+	}
+
+	if rr := do(http.MethodPost, "/api/route/reset", "7"); rr.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", rr.Code)
+	}
+	if n := routeLen("7"); n != 2 {
+		t.Fatalf("expected direct route after reset, got %d waypoints", n)
+	}
+}
@@ -0,0 +1,36 @@
+package auth
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CharacterFromToken returns the character ID from an EVE SSO access token.
+// The token is a JWT whose "sub" claim has the form "CHARACTER:EVE:<id>".
+// The signature is not checked: the token is received directly from the
+// token endpoint over TLS.
+func CharacterFromToken(accessToken string) (int, error) {
+	parts := strings.Split(accessToken, ".")
+	if len(parts) != 3 {
+		return 0, errors.New("access token is not a JWT")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return 0, fmt.Errorf("access token payload: %w", err)
+	}
+	var claims struct {
+		Sub string `json:"sub"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return 0, fmt.Errorf("access token payload: %w", err)
+	}
+	id, err := strconv.Atoi(strings.TrimPrefix(claims.Sub, "CHARACTER:EVE:"))
+	if err != nil || !strings.HasPrefix(claims.Sub, "CHARACTER:EVE:") {
+		return 0, fmt.Errorf("unexpected subject %q", claims.Sub)
+	}
+	return id, nil
+}
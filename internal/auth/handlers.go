@@ -12,6 +12,8 @@ import (
 type Handler struct {
 	Config *oauth2.Config
 	Store  *TokenStore
+	// Sessions, if set, receives the ID of the logged in character.
+	Sessions *Manager
 }
 
 // NewHandler creates a new Handler.
@@ -46,6 +48,19 @@ func (h *Handler) Callback(w http.ResponseWriter, r *http.Request) {
 	if _, err := h.Store.Load(1); err != nil {
 		log.Println("token load failed:", err)
 	}
+	if h.Sessions != nil {
+		id, err := CharacterFromToken(token.AccessToken)
+		if err != nil {
+			log.Println("character lookup failed:", err)
+			http.Error(w, "invalid token", http.StatusInternalServerError)
+			return
+		}
+		if err := h.Sessions.SetCharacterID(w, r, id); err != nil {
+			log.Println("session save failed:", err)
+			http.Error(w, "session failed", http.StatusInternalServerError)
+			return
+		}
+	}
 	w.WriteHeader(http.StatusOK)
 	_, _ = w.Write([]byte("ok"))
 }
@@ -2,6 +2,7 @@ package auth
 
 import (
 	"database/sql"
+	"encoding/base64"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -73,3 +74,58 @@ func TestCallback(t *testing.T) {
 		t.Fatalf("unexpected tokens: %s %s", access, refresh)
 	}
 }
+
+func TestCallbackSession(t *testing.T) {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"CHARACTER:EVE:96061222","name":"Pilot"}`))
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token": "header." + payload + ".signature",
+			"token_type":   "Bearer",
+			"expires_in":   1200,
+		})
+	}))
+	defer ts.Close()
+	conf := &oauth2.Config{Endpoint: oauth2.Endpoint{AuthURL: ts.URL, TokenURL: ts.URL}}
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := NewTokenStore(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SESSION_KEY", "0123456789abcdef0123456789abcdef")
+	h := NewHandler(conf, store)
+	h.Sessions = NewManager()
+
+	rr := httptest.NewRecorder()
+	h.Callback(rr, httptest.NewRequest(http.MethodGet, "/callback?code=1", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, rr.Code, rr.Body)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, c := range rr.Result().Cookies() {
+		req.AddCookie(c)
+	}
+	if id, ok := h.Sessions.CharacterID(req); !ok || id != 96061222 {
+		t.Fatalf("unexpected character: %d %v", id, ok)
+	}
+	if _, ok := h.Sessions.CharacterID(httptest.NewRequest(http.MethodGet, "/", nil)); ok {
+		t.Fatal("expected no character without session cookie")
+	}
+}
+
+func TestCharacterFromToken(t *testing.T) {
+	enc := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+	for _, tok := range []string{
+		"abc",
+		"a." + enc("not json") + ".c",
+		"a." + enc(`{"sub":"CORPORATION:EVE:1"}`) + ".c",
+		"a." + enc(`{"sub":"CHARACTER:EVE:x"}`) + ".c",
+	} {
+		if _, err := CharacterFromToken(tok); err == nil {
+			t.Errorf("%q: expected error", tok)
+		}
+	}
+}
@@ -27,3 +27,32 @@ func NewManager() *Manager {
 func (m *Manager) Get(r *http.Request, name string) (*sessions.Session, error) {
 	return m.store.Get(r, name)
 }
+
+const (
+	// sessionName is the name of the session cookie.
+	sessionName = "eve-route"
+	// characterKey is the session value holding the EVE character ID.
+	characterKey = "character_id"
+)
+
+// CharacterID returns the ID of the character logged in with the request's
+// session; ok is false if there is no such character.
+func (m *Manager) CharacterID(r *http.Request) (id int, ok bool) {
+	s, err := m.Get(r, sessionName)
+	if err != nil {
+		return 0, false
+	}
+	id, ok = s.Values[characterKey].(int)
+	return id, ok && id > 0
+}
+
+// SetCharacterID stores the character ID in the session.
+func (m *Manager) SetCharacterID(w http.ResponseWriter, r *http.Request, id int) error {
+	s, err := m.Get(r, sessionName)
+	if err != nil {
+		// a cookie signed with an old key; start a new session
+		log.Printf("session: %v", err)
+	}
+	s.Values[characterKey] = id
+	return s.Save(r, w)
+}
@@ -0,0 +1,140 @@
+package capital
+
+import (
+	"fmt"
+	"math"
+)
+
+// CynoType — тип цинозурального поля, на которое открывается мост.
+type CynoType string
+
+const (
+	CynoNormal CynoType = "normal"
+	CynoCovert CynoType = "covert"
+)
+
+// massUnit — единица массы для расхода топлива моста (1 000 000 кг).
+const massUnit = 1e6
+
+// BridgeProfile описывает портальный генератор корабля.
+type BridgeProfile struct {
+	Ship ShipProfile `json:"ship"`
+	// Cyno — цино, которого достаточно для моста: Black Ops открывает мост на
+	// скрытое цино, титан — только на обычное.
+	Cyno CynoType `json:"cyno"`
+	// FuelPerMass — расход изотопов на световой год за каждые 1 000 000 кг флота.
+	FuelPerMass float64 `json:"fuelPerMass"`
+	// ShipMass — масса одного корабля флота по умолчанию в килограммах
+	// (стелс-бомбер для Black Ops, линкор для титана).
+	ShipMass float64 `json:"shipMass"`
+	// Fleet — какие корабли могут пройти через мост.
+	Fleet string `json:"fleet"`
+}
+
+// bridgeProfiles — корабли с портальным генератором.
+var bridgeProfiles = map[ShipClass]BridgeProfile{
+	ShipBlackOps: {Cyno: CynoCovert, FuelPerMass: 1, ShipMass: 1_300_000, Fleet: "covert ops capable ships"},
+	ShipTitan:    {Cyno: CynoNormal, FuelPerMass: 0.5, ShipMass: 100_000_000, Fleet: "subcapital ships"},
+}
+
+// Bridge возвращает профиль портального генератора для класса кораблей.
+func Bridge(class string) (BridgeProfile, error) {
+	ship, err := Ship(class)
+	if err != nil {
+		return BridgeProfile{}, err
+	}
+	b, ok := bridgeProfiles[ship.Class]
+	if !ok {
+		return BridgeProfile{}, fmt.Errorf("%s cannot open a jump portal", ship.Name)
+	}
+	b.Ship = ship
+	return b, nil
+}
+
+// BridgeOptions — параметры цепочки мостов.
+type BridgeOptions struct {
+	// JDC — уровень навыка Jump Drive Calibration.
+	JDC int
+	// Mass — масса флота в килограммах; если 0, считается как Ships × BridgeProfile.ShipMass.
+	Mass  float64
+	Ships int
+	// Race и Skills — раса корабля и навыки для расчёта его собственных прыжков.
+	Race   string
+	Skills FuelSkills
+	// Avoid и Prefer — как в Options.
+	Avoid  []string
+	Prefer []string
+}
+
+// BridgeHop — один мост цепочки. PortalFuel — топливо на перенос флота,
+// ShipFuel — на прыжок самого корабля вслед за флотом (0 на последнем мосту).
+type BridgeHop struct {
+	From       string   `json:"from"`
+	To         string   `json:"to"`
+	Distance   float64  `json:"distance"`
+	Cyno       CynoType `json:"cyno"`
+	PortalFuel int      `json:"portalFuel"`
+	ShipFuel   int      `json:"shipFuel"`
+}
+
+// BridgePlan — цепочка мостов от точки сбора до цели.
+type BridgePlan struct {
+	Bridge     BridgeProfile `json:"bridge"`
+	Range      float64       `json:"range"`
+	Mass       float64       `json:"mass"`
+	Isotope    string        `json:"isotope,omitempty"`
+	Hops       []BridgeHop   `json:"hops"`
+	PortalFuel int           `json:"portalFuel"`
+	ShipFuel   int           `json:"shipFuel"`
+	TotalFuel  int           `json:"totalFuel"`
+}
+
+// PlanBridge рассчитывает цепочку мостов для флота от staging до target:
+// наименьшее число мостов, при равном числе — наименьшее расстояние.
+// После каждого моста, кроме последнего, корабль прыгает на то же цино,
+// чтобы открыть следующий мост.
+func (p *Planner) PlanBridge(staging, target string, b BridgeProfile, opts BridgeOptions) (BridgePlan, error) {
+	jumpRange, err := b.Ship.Range(opts.JDC)
+	if err != nil {
+		return BridgePlan{}, err
+	}
+	mass := opts.Mass
+	if mass == 0 {
+		mass = float64(opts.Ships) * b.ShipMass
+	}
+	if mass <= 0 {
+		return BridgePlan{}, fmt.Errorf("fleet mass or size is required")
+	}
+	path, err := p.PlanWithOptions(staging, target, Options{
+		Range:    jumpRange,
+		Optimize: OptimizeJumpsDistance,
+		Ship:     b.Ship,
+		Avoid:    opts.Avoid,
+		Prefer:   opts.Prefer,
+	})
+	if err != nil {
+		return BridgePlan{}, err
+	}
+	fuel, err := b.Ship.Fuel(path, opts.Race, opts.Skills)
+	if err != nil {
+		return BridgePlan{}, err
+	}
+	res := BridgePlan{Bridge: b, Range: jumpRange, Mass: mass, Isotope: fuel.Isotope, Hops: []BridgeHop{}}
+	for i, leg := range fuel.Legs {
+		hop := BridgeHop{
+			From:       leg.From,
+			To:         leg.To,
+			Distance:   leg.Distance,
+			Cyno:       b.Cyno,
+			PortalFuel: int(math.Ceil(leg.Distance*mass/massUnit*b.FuelPerMass - 1e-9)),
+		}
+		if i < len(fuel.Legs)-1 {
+			hop.ShipFuel = leg.Fuel
+		}
+		res.Hops = append(res.Hops, hop)
+		res.PortalFuel += hop.PortalFuel
+		res.ShipFuel += hop.ShipFuel
+	}
+	res.TotalFuel = res.PortalFuel + res.ShipFuel
+	return res, nil
+}
@@ -0,0 +1,54 @@
+package capital
+
+import (
+	"testing"
+
+	dbstore "github.com/tkhamez/eve-route-go/internal/dbstore"
+)
+
+func TestPlanBridge(t *testing.T) {
+	systems := map[int]System{}
+	for _, s := range line(5, 5) {
+		systems[s.ID] = s
+	}
+	p, err := NewPlanner(dbstore.NewMemory(nil, nil, systems), 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	blops, err := Bridge("blackops")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	plan, err := p.PlanBridge("S0", "S2", blops, BridgeOptions{JDC: 5, Ships: 10, Race: "amarr", Skills: FuelSkills{JFC: 5}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if plan.Range != 8 || plan.Mass != 13_000_000 || plan.Isotope != "Oxygen Isotopes" || len(plan.Hops) != 2 {
+		t.Fatalf("unexpected plan: %+v", plan)
+	}
+	// 5 св. лет × 13 × 1 = 65 изотопов на мост; корабль прыгает только после первого моста:
+	// 700 × 0,5 × 5 = 1750
+	for _, hop := range plan.Hops {
+		if hop.Cyno != CynoCovert || hop.PortalFuel != 65 {
+			t.Errorf("unexpected hop: %+v", hop)
+		}
+	}
+	if plan.Hops[0].ShipFuel != 1750 || plan.Hops[1].ShipFuel != 0 || plan.TotalFuel != 1880 {
+		t.Fatalf("unexpected fuel: %+v", plan)
+	}
+
+	titan, _ := Bridge("titan")
+	plan, err = p.PlanBridge("S0", "S2", titan, BridgeOptions{JDC: 5, Mass: 1e9})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if plan.Hops[0].Cyno != CynoNormal || plan.PortalFuel != 5000 {
+		t.Fatalf("unexpected titan plan: %+v", plan)
+	}
+	if _, err := p.PlanBridge("S0", "S2", titan, BridgeOptions{JDC: 5}); err == nil {
+		t.Error("expected error without fleet mass")
+	}
+	if _, err := Bridge("carrier"); err == nil {
+		t.Error("expected error for carrier")
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"embed"
 	"encoding/json"
 	"log"
+
+	"github.com/tkhamez/eve-route-go/internal/graph"
 )
 
 //go:embed systems.json
@@ -30,3 +32,25 @@ func loadSystems() map[int]System {
 func DefaultSystems() map[int]System {
 	return loadSystems()
 }
+
+// SystemsFromGraph возвращает системы графа карты, для которых известны координаты.
+// Так планировщик использует те же данные, что и поиск маршрутов по воротам.
+func SystemsFromGraph(g graph.Graph) map[int]System {
+	systems := make(map[int]System)
+	for _, s := range g.Systems {
+		if !s.HasPosition() {
+			continue
+		}
+		systems[s.ID] = System{
+			ID:       s.ID,
+			Name:     s.Name,
+			X:        s.X,
+			Y:        s.Y,
+			Z:        s.Z,
+			Security: s.Security,
+			RegionID: s.RegionID,
+			Region:   g.Regions[s.RegionID],
+		}
+	}
+	return systems
+}
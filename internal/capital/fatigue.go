@@ -0,0 +1,66 @@
+package capital
+
+import "fmt"
+
+const (
+	// MaxFatigue — предел усталости от прыжков в минутах (5 часов).
+	MaxFatigue = 300.0
+	// maxReactivation — предел таймера повторной активации в минутах.
+	maxReactivation = 30.0
+	// minFatigueFactor — усталость перед прыжком считается не меньше 10 минут.
+	minFatigueFactor = 10.0
+)
+
+// TimelineLeg — один прыжок маршрута с учётом усталости.
+// Все времена указаны в минутах от начала маршрута.
+type TimelineLeg struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Distance — расстояние прыжка, EffectiveDistance — с учётом бонуса корабля к усталости.
+	Distance          float64 `json:"distance"`
+	EffectiveDistance float64 `json:"effectiveDistance"`
+	// DepartAt — самое раннее время прыжка.
+	DepartAt float64 `json:"departAt"`
+	// FatigueBefore и FatigueAfter — усталость (оранжевый таймер) перед прыжком и после него.
+	FatigueBefore float64 `json:"fatigueBefore"`
+	FatigueAfter  float64 `json:"fatigueAfter"`
+	// Reactivation — таймер повторной активации (синий таймер) после прыжка.
+	Reactivation float64 `json:"reactivation"`
+}
+
+// Timeline рассчитывает для маршрута path усталость и таймеры повторной активации.
+// startFatigue — усталость пилота в минутах перед первым прыжком.
+// Модель:
+//
+//	эффективное расстояние = расстояние × (1 − бонус корабля)
+//	повторная активация    = min(30, max(1 + эфф. расстояние, усталость / 10))
+//	усталость после прыжка = min(300, max(усталость, 10) × (1 + эфф. расстояние))
+//
+// Следующий прыжок выполняется сразу по окончании повторной активации,
+// за это время усталость уменьшается на столько же минут.
+func Timeline(path []System, ship ShipProfile, startFatigue float64) ([]TimelineLeg, error) {
+	if startFatigue < 0 || startFatigue > MaxFatigue {
+		return nil, fmt.Errorf("invalid fatigue %.1f", startFatigue)
+	}
+	legs := make([]TimelineLeg, 0, max(len(path)-1, 0))
+	fatigue := startFatigue
+	var at float64
+	for i := 1; i < len(path); i++ {
+		ly := Distance(path[i-1], path[i])
+		eff := ly * (1 - ship.FatigueBonus)
+		leg := TimelineLeg{
+			From:              path[i-1].Name,
+			To:                path[i].Name,
+			Distance:          ly,
+			EffectiveDistance: eff,
+			DepartAt:          at,
+			FatigueBefore:     fatigue,
+			FatigueAfter:      min(MaxFatigue, max(fatigue, minFatigueFactor)*(1+eff)),
+			Reactivation:      min(maxReactivation, max(1+eff, fatigue/10)),
+		}
+		legs = append(legs, leg)
+		at += leg.Reactivation
+		fatigue = max(0, leg.FatigueAfter-leg.Reactivation)
+	}
+	return legs, nil
+}
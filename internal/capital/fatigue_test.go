@@ -0,0 +1,59 @@
+package capital
+
+import (
+	"math"
+	"testing"
+)
+
+// line возвращает системы на оси X с указанными расстояниями между соседними.
+func line(distances ...float64) []System {
+	path := []System{{ID: 1, Name: "S0"}}
+	var x float64
+	for i, d := range distances {
+		x += d * lyInMeters
+		path = append(path, System{ID: i + 2, Name: "S" + string(rune('1'+i)), X: x})
+	}
+	return path
+}
+
+func TestTimeline(t *testing.T) {
+	carrier, _ := Ship("carrier")
+	legs, err := Timeline(line(5, 5), carrier, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(legs) != 2 {
+		t.Fatalf("expected 2 legs, got %d", len(legs))
+	}
+	// первый прыжок: усталость 10×6 = 60, повторная активация 1+5 = 6
+	first := legs[0]
+	if !near(first.FatigueAfter, 60) || !near(first.Reactivation, 6) || first.DepartAt != 0 {
+		t.Errorf("unexpected first leg: %+v", first)
+	}
+	// второй прыжок через 6 минут: усталость 54×6 = 324 → предел 300
+	second := legs[1]
+	if !near(second.DepartAt, 6) || !near(second.FatigueBefore, 54) || !near(second.FatigueAfter, MaxFatigue) || !near(second.Reactivation, 6) {
+		t.Errorf("unexpected second leg: %+v", second)
+	}
+}
+
+func TestTimelineShipBonus(t *testing.T) {
+	jf, _ := Ship("jf")
+	legs, err := Timeline(line(10), jf, 100)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// эффективное расстояние 1 св. год; повторная активация max(2, 100/10) = 10
+	leg := legs[0]
+	if !near(leg.EffectiveDistance, 1) || !near(leg.Reactivation, 10) || !near(leg.FatigueAfter, 200) {
+		t.Errorf("unexpected leg: %+v", leg)
+	}
+	if _, err := Timeline(line(1), jf, -1); err == nil {
+		t.Error("expected error for negative fatigue")
+	}
+	if legs, _ := Timeline(line(), jf, 0); len(legs) != 0 {
+		t.Errorf("expected no legs, got %+v", legs)
+	}
+}
+
+func near(a, b float64) bool { return math.Abs(a-b) < 1e-6 }
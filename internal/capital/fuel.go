@@ -0,0 +1,83 @@
+package capital
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+const (
+	// jfcBonus — уменьшение расхода топлива за уровень навыка Jump Fuel Conservation.
+	jfcBonus = 0.1
+	// MaxSkillLevel — максимальный уровень навыков Jump Fuel Conservation и корпуса.
+	MaxSkillLevel = 5
+)
+
+// isotopes — изотопы, которые расходуют корабли каждой расы.
+var isotopes = map[string]string{
+	"amarr":    "Oxygen Isotopes",
+	"caldari":  "Nitrogen Isotopes",
+	"gallente": "Helium Isotopes",
+	"minmatar": "Hydrogen Isotopes",
+}
+
+// Isotope возвращает название изотопа для расы корабля (без учёта регистра).
+func Isotope(race string) (string, error) {
+	name, ok := isotopes[strings.ToLower(race)]
+	if !ok {
+		return "", fmt.Errorf("unknown race %q", race)
+	}
+	return name, nil
+}
+
+// FuelSkills — уровни навыков, влияющих на расход топлива.
+type FuelSkills struct {
+	// JFC — уровень навыка Jump Fuel Conservation.
+	JFC int `json:"jfc"`
+	// Hull — уровень навыка корпуса (например, Jump Freighters).
+	Hull int `json:"hull"`
+}
+
+// FuelLeg — расход топлива на одном прыжке.
+type FuelLeg struct {
+	From     string  `json:"from"`
+	To       string  `json:"to"`
+	Distance float64 `json:"distance"`
+	Fuel     int     `json:"fuel"`
+}
+
+// Fuel — расход топлива на маршруте. Isotope пустой, если раса корабля не указана.
+type Fuel struct {
+	Isotope string    `json:"isotope,omitempty"`
+	PerLY   float64   `json:"perLy"`
+	Legs    []FuelLeg `json:"legs"`
+	Total   int       `json:"total"`
+}
+
+// Fuel рассчитывает расход изотопов на маршруте path. Расход на световой год равен
+// FuelPerLY × (1 − 0,1 × JFC) × (1 − HullFuelBonus × Hull); расход на прыжок
+// округляется вверх, как в игре.
+func (p ShipProfile) Fuel(path []System, race string, skills FuelSkills) (Fuel, error) {
+	if skills.JFC < 0 || skills.JFC > MaxSkillLevel {
+		return Fuel{}, fmt.Errorf("invalid JFC level %d", skills.JFC)
+	}
+	if skills.Hull < 0 || skills.Hull > MaxSkillLevel {
+		return Fuel{}, fmt.Errorf("invalid hull skill level %d", skills.Hull)
+	}
+	res := Fuel{Legs: make([]FuelLeg, 0, max(len(path)-1, 0))}
+	if race != "" {
+		var err error
+		if res.Isotope, err = Isotope(race); err != nil {
+			return Fuel{}, err
+		}
+	}
+	res.PerLY = p.FuelPerLY * (1 - jfcBonus*float64(skills.JFC)) * (1 - p.HullFuelBonus*float64(skills.Hull))
+	for i := 1; i < len(path); i++ {
+		ly := Distance(path[i-1], path[i])
+		// погрешность вычислений с плавающей точкой не должна добавлять лишний изотоп
+		fuel := int(math.Ceil(ly*res.PerLY - 1e-9))
+		res.Legs = append(res.Legs, FuelLeg{From: path[i-1].Name, To: path[i].Name, Distance: ly, Fuel: fuel})
+		res.Total += fuel
+	}
+	return res, nil
+}
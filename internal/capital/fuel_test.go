@@ -0,0 +1,41 @@
+package capital
+
+import "testing"
+
+func TestFuel(t *testing.T) {
+	jf, _ := Ship("jf")
+	fuel, err := jf.Fuel(line(2.5, 4), "Gallente", FuelSkills{JFC: 5, Hull: 4})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 10000 × 0,5 × 0,6 = 3000 изотопов на световой год
+	if fuel.Isotope != "Helium Isotopes" || !near(fuel.PerLY, 3000) {
+		t.Fatalf("unexpected fuel: %+v", fuel)
+	}
+	if len(fuel.Legs) != 2 || fuel.Legs[0].Fuel != 7500 || fuel.Legs[1].Fuel != 12000 || fuel.Total != 19500 {
+		t.Fatalf("unexpected legs: %+v", fuel)
+	}
+
+	// бонус корпуса есть не у всех кораблей, расход округляется вверх
+	carrier, _ := Ship("carrier")
+	fuel, err = carrier.Fuel(line(1.0001), "", FuelSkills{Hull: 5})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fuel.Isotope != "" || fuel.Total != 3001 {
+		t.Fatalf("unexpected fuel: %+v", fuel)
+	}
+
+	for _, tc := range []struct {
+		race   string
+		skills FuelSkills
+	}{
+		{"jove", FuelSkills{}},
+		{"", FuelSkills{JFC: 6}},
+		{"", FuelSkills{Hull: -1}},
+	} {
+		if _, err := jf.Fuel(line(1), tc.race, tc.skills); err == nil {
+			t.Errorf("expected error for %+v", tc)
+		}
+	}
+}
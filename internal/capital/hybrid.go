@@ -0,0 +1,239 @@
+package capital
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	routepkg "github.com/tkhamez/eve-route-go/internal/route"
+)
+
+const (
+	// DefaultGateCost — стоимость перехода через врата в гибридном маршруте.
+	DefaultGateCost = 1.0
+	// DefaultJumpCost — стоимость прыжка; прыжок дороже врат из-за усталости и топлива.
+	DefaultJumpCost = 3.0
+)
+
+// LegType — способ перемещения на шаге гибридного маршрута.
+type LegType string
+
+const (
+	LegGate LegType = "gate"
+	LegJump LegType = "jump"
+)
+
+// HybridOptions — параметры гибридного маршрута.
+type HybridOptions struct {
+	// Ship — корабль; определяет допустимые системы.
+	Ship ShipProfile
+	// Range — дальность прыжка в световых годах.
+	Range float64
+	// GateCost и JumpCost — стоимость одного перехода через врата и одного прыжка;
+	// 0 означает значения по умолчанию.
+	GateCost float64
+	JumpCost float64
+}
+
+// HybridLeg — шаг гибридного маршрута. Connection — тип врат (Stargate или Ansiblex)
+// для шагов через врата, Distance — расстояние прыжка в световых годах.
+type HybridLeg struct {
+	Type       LegType               `json:"type"`
+	From       string                `json:"from"`
+	To         string                `json:"to"`
+	Connection routepkg.WaypointType `json:"connection,omitempty"`
+	Distance   float64               `json:"distance,omitempty"`
+}
+
+// Itinerary — гибридный маршрут.
+type Itinerary struct {
+	Legs     []HybridLeg `json:"legs"`
+	Gates    int         `json:"gates"`
+	Jumps    int         `json:"jumps"`
+	Distance float64     `json:"distance"`
+	Cost     float64     `json:"cost"`
+}
+
+// Hybrid прокладывает маршруты, сочетающие врата и Ansiblex маршрутизатора
+// с прыжками планировщика капитальных кораблей.
+type Hybrid struct {
+	routes  routepkg.Source
+	planner *Planner
+
+	mu sync.Mutex
+	// indexed — маршрутизатор, для которого построен index.
+	indexed *routepkg.Route
+	// index — индекс узла маршрутизатора по ID системы.
+	index map[int]int
+}
+
+// NewHybrid создаёт гибридный планировщик поверх текущего маршрутизатора routes.
+// Маршрутизатор и планировщик неизменяемы, поэтому гибридный планировщик можно
+// использовать из нескольких горутин одновременно.
+func NewHybrid(routes routepkg.Source, p *Planner) *Hybrid {
+	return &Hybrid{routes: routes, planner: p}
+}
+
+// nodeIndex возвращает индексы узлов r по ID систем; индекс строится один раз
+// для каждого маршрутизатора.
+func (h *Hybrid) nodeIndex(r *routepkg.Route) map[int]int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.indexed != r {
+		h.index = make(map[int]int, len(r.Nodes()))
+		for _, n := range r.Nodes() {
+			h.index[n.Value.ID] = n.Index()
+		}
+		h.indexed = r
+	}
+	return h.index
+}
+
+// hybridEdge — переход из узла в узел.
+type hybridEdge struct {
+	to   int
+	leg  LegType
+	conn routepkg.WaypointType
+	ly   float64
+}
+
+// Plan ищет маршрут наименьшей стоимости от startName до endName. Через врата корабль
+// может попасть в высокую безопасность, только если ему разрешено там находиться
+// (ShipProfile.JumpFromHighsec); в червоточины, Pochven и Zarzakh корабль не попадает.
+// Для прыжков действуют правила CanJumpFrom и CanJumpTo. Временные соединения не используются.
+func (h *Hybrid) Plan(ctx context.Context, startName, endName string, opts HybridOptions) (Itinerary, error) {
+	if opts.Range <= 0 {
+		return Itinerary{}, fmt.Errorf("invalid range %.2f", opts.Range)
+	}
+	gateCost, jumpCost := opts.GateCost, opts.JumpCost
+	if gateCost == 0 {
+		gateCost = DefaultGateCost
+	}
+	if jumpCost == 0 {
+		jumpCost = DefaultJumpCost
+	}
+	if gateCost < 0 || jumpCost < 0 {
+		return Itinerary{}, errors.New("costs must not be negative")
+	}
+	route := h.routes.Route()
+	nodes := route.Nodes()
+	index := h.nodeIndex(route)
+	start, end := -1, -1
+	for _, n := range nodes {
+		if strings.EqualFold(n.Value.Name, startName) {
+			start = n.Index()
+		}
+		if strings.EqualFold(n.Value.Name, endName) {
+			end = n.Index()
+		}
+	}
+	if start < 0 || end < 0 {
+		return Itinerary{}, errors.New("system not found")
+	}
+	log.Printf("capital hybrid: %s -> %s (%.2f ly)", startName, endName, opts.Range)
+
+	// стоимость: jumps и other — число прыжков и переходов через врата, ly — расстояние прыжков
+	weight := func(c cost) float64 { return float64(c.jumps)*jumpCost + float64(c.other)*gateCost }
+	less := func(a, b cost) bool {
+		if wa, wb := weight(a), weight(b); wa != wb {
+			return wa < wb
+		}
+		return a.ly < b.ly
+	}
+	best := map[int]cost{start: {}}
+	parent := map[int]hybridEdge{}
+	from := map[int]int{}
+	done := map[int]bool{}
+	q := &costQueue{less: less}
+	heap.Push(q, queued{id: start})
+	for steps := 0; q.Len() > 0; steps++ {
+		if steps%256 == 0 {
+			if err := ctx.Err(); err != nil {
+				return Itinerary{}, err
+			}
+		}
+		cur := heap.Pop(q).(queued)
+		if done[cur.id] {
+			continue
+		}
+		done[cur.id] = true
+		if cur.id == end {
+			break
+		}
+		for _, e := range h.edges(nodes[cur.id], index, opts) {
+			if done[e.to] {
+				continue
+			}
+			c := cur.cost
+			if e.leg == LegJump {
+				c.jumps++
+				c.ly += e.ly
+			} else {
+				c.other++
+			}
+			if old, ok := best[e.to]; !ok || less(c, old) {
+				best[e.to] = c
+				parent[e.to] = e
+				from[e.to] = cur.id
+				heap.Push(q, queued{id: e.to, cost: c})
+			}
+		}
+	}
+	if !done[end] {
+		return Itinerary{}, errors.New("route not found")
+	}
+
+	res := Itinerary{Legs: []HybridLeg{}, Cost: weight(best[end]), Distance: best[end].ly}
+	for v := end; v != start; v = from[v] {
+		e := parent[v]
+		res.Legs = append(res.Legs, HybridLeg{
+			Type:       e.leg,
+			From:       nodes[from[v]].Value.Name,
+			To:         nodes[v].Value.Name,
+			Connection: e.conn,
+			Distance:   e.ly,
+		})
+		if e.leg == LegJump {
+			res.Jumps++
+		} else {
+			res.Gates++
+		}
+	}
+	for i, j := 0, len(res.Legs)-1; i < j; i, j = i+1, j-1 {
+		res.Legs[i], res.Legs[j] = res.Legs[j], res.Legs[i]
+	}
+	return res, nil
+}
+
+// edges возвращает допустимые переходы из узла n: сначала врата, затем прыжки по возрастанию ID.
+func (h *Hybrid) edges(n *routepkg.Node, index map[int]int, opts HybridOptions) []hybridEdge {
+	var res []hybridEdge
+	for _, c := range n.Connections() {
+		if c.Type == routepkg.TypeTemporary {
+			continue
+		}
+		to := c.Node.Value
+		target := System{ID: to.ID, Name: to.Name, Security: to.Security, RegionID: to.RegionID}
+		if restricted(target) != "" || Highsec(target) && !opts.Ship.JumpFromHighsec {
+			continue
+		}
+		res = append(res, hybridEdge{to: c.Node.Index(), leg: LegGate, conn: c.Type})
+	}
+	origin, ok := h.planner.systems[n.Value.ID]
+	if !ok || CanJumpFrom(origin, opts.Ship) != nil {
+		return res
+	}
+	for _, id := range h.planner.neighbors(origin.ID, opts.Range) {
+		target := h.planner.systems[id]
+		to, ok := index[id]
+		if !ok || CanJumpTo(target) != nil {
+			continue
+		}
+		res = append(res, hybridEdge{to: to, leg: LegJump, ly: Distance(origin, target)})
+	}
+	return res
+}
@@ -0,0 +1,93 @@
+package capital
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	dbstore "github.com/tkhamez/eve-route-go/internal/dbstore"
+	"github.com/tkhamez/eve-route-go/internal/graph"
+	routepkg "github.com/tkhamez/eve-route-go/internal/route"
+)
+
+// hybridFixture — цепочка врат из высокой безопасности в низкую.
+// Координаты L2 неизвестны, поэтому туда можно попасть только через врата.
+func hybridFixture(t *testing.T) *Hybrid {
+	t.Helper()
+	g := graph.Graph{Regions: map[int]string{1: "Region"}}
+	for i, s := range []struct {
+		name     string
+		x        float64
+		security float64
+	}{{"H1", 0, 0.9}, {"H2", 1, 0.9}, {"H3", 2, 0.8}, {"L1", 11, 0.3}, {"L2", -1, 0.2}} {
+		g.Systems = append(g.Systems, graph.System{ID: i + 1, Name: s.name, RegionID: 1, Security: s.security, X: (s.x + 1) * lyInMeters})
+	}
+	g.Connections = [][2]int{{1, 2}, {2, 3}, {3, 4}, {4, 5}}
+	store := dbstore.NewMemory(nil, nil, SystemsFromGraph(g))
+	r, err := routepkg.NewRouteFromGraph(g, store, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p, err := NewPlanner(store, 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return NewHybrid(r, p)
+}
+
+func legs(it Itinerary) string {
+	var res []string
+	for _, l := range it.Legs {
+		res = append(res, string(l.Type)+":"+l.To)
+	}
+	return strings.Join(res, " ")
+}
+
+func TestHybridPlan(t *testing.T) {
+	h := hybridFixture(t)
+	jf, _ := Ship("jf")
+
+	// по умолчанию врата дешевле прыжков
+	it, err := h.Plan(context.Background(), "H1", "L2", HybridOptions{Ship: jf, Range: 10})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := legs(it); got != "gate:H2 gate:H3 gate:L1 gate:L2" || it.Gates != 4 || it.Jumps != 0 {
+		t.Fatalf("unexpected itinerary: %s", got)
+	}
+
+	// дорогие врата: из высокой безопасности прыгаем, последний шаг — через врата
+	it, err = h.Plan(context.Background(), "H1", "L2", HybridOptions{Ship: jf, Range: 10, GateCost: 3, JumpCost: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := legs(it); got != "gate:H2 jump:L1 gate:L2" || it.Jumps != 1 || it.Gates != 2 || it.Cost != 7 {
+		t.Fatalf("unexpected itinerary: %s %+v", got, it)
+	}
+	if it.Legs[0].Connection != routepkg.TypeStargate || it.Legs[1].Distance != 10 {
+		t.Fatalf("unexpected legs: %+v", it.Legs)
+	}
+
+	// карриер не может находиться в высокой безопасности
+	carrier, _ := Ship("carrier")
+	if _, err := h.Plan(context.Background(), "H1", "L2", HybridOptions{Ship: carrier, Range: 7}); err == nil {
+		t.Fatal("expected route not found for carrier")
+	}
+	// Black Ops, как и JF, проходит высокую безопасность через врата и прыгает оттуда
+	blackOps, _ := Ship("blackops")
+	it, err = h.Plan(context.Background(), "H1", "L2", HybridOptions{Ship: blackOps, Range: 10, GateCost: 3, JumpCost: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := legs(it); got != "gate:H2 jump:L1 gate:L2" {
+		t.Fatalf("unexpected Black Ops itinerary: %s", got)
+	}
+	if _, err := h.Plan(context.Background(), "H1", "Nowhere", HybridOptions{Ship: jf, Range: 10}); err == nil {
+		t.Fatal("expected system not found")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := h.Plan(ctx, "H1", "L2", HybridOptions{Ship: jf, Range: 10}); err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package capital
+
+import (
+	"math"
+	"sort"
+)
+
+// gridCell — размер ячейки пространственного индекса в световых годах.
+// Соответствует типичной дальности прыжка, поэтому запрос по радиусу
+// просматривает несколько десятков ячеек.
+const gridCell = 5.0
+
+type cellKey [3]int
+
+// spatialIndex — равномерная сетка по координатам систем.
+// Строится один раз и после этого не изменяется.
+type spatialIndex struct {
+	cells map[cellKey][]System
+}
+
+func newSpatialIndex(systems map[int]System) *spatialIndex {
+	ix := &spatialIndex{cells: map[cellKey][]System{}}
+	for _, s := range systems {
+		k := cellOf(s)
+		ix.cells[k] = append(ix.cells[k], s)
+	}
+	return ix
+}
+
+// cellOf возвращает ячейку, в которую попадает система.
+func cellOf(s System) cellKey {
+	c := func(v float64) int { return int(math.Floor(v / lyInMeters / gridCell)) }
+	return cellKey{c(s.X), c(s.Y), c(s.Z)}
+}
+
+// within возвращает ID систем на расстоянии не больше radius световых лет
+// от системы center (кроме неё самой), упорядоченные по возрастанию.
+func (ix *spatialIndex) within(center System, radius float64) []int {
+	var res []int
+	add := func(list []System) {
+		for _, s := range list {
+			if s.ID != center.ID && Distance(center, s) <= radius {
+				res = append(res, s.ID)
+			}
+		}
+	}
+	c := cellOf(center)
+	span := int(math.Ceil(radius / gridCell))
+	// при большом радиусе дешевле просмотреть все непустые ячейки
+	if side := float64(2*span + 1); side*side*side > float64(len(ix.cells)) {
+		for _, list := range ix.cells {
+			add(list)
+		}
+		sort.Ints(res)
+		return res
+	}
+	for x := c[0] - span; x <= c[0]+span; x++ {
+		for y := c[1] - span; y <= c[1]+span; y++ {
+			for z := c[2] - span; z <= c[2]+span; z++ {
+				add(ix.cells[cellKey{x, y, z}])
+			}
+		}
+	}
+	sort.Ints(res)
+	return res
+}
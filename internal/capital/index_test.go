@@ -0,0 +1,137 @@
+package capital
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"reflect"
+	"sort"
+	"testing"
+
+	dbstore "github.com/tkhamez/eve-route-go/internal/dbstore"
+	"github.com/tkhamez/eve-route-go/internal/graph"
+)
+
+// universeSystems возвращает синтетическую карту размером с k-space EVE: системы
+// случайно распределены в объёме 120×20×120 световых лет. Если задана переменная
+// EVE_ROUTE_GRAPH, вместо неё загружаются системы настоящего графа из JSON-файла.
+func universeSystems(tb testing.TB, count int) map[int]System {
+	tb.Helper()
+	if path := os.Getenv("EVE_ROUTE_GRAPH"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			tb.Fatalf("read graph: %v", err)
+		}
+		var g graph.Graph
+		if err := json.Unmarshal(data, &g); err != nil {
+			tb.Fatalf("unmarshal graph: %v", err)
+		}
+		return SystemsFromGraph(g)
+	}
+	rnd := rand.New(rand.NewSource(42))
+	systems := make(map[int]System, count)
+	for i := 0; i < count; i++ {
+		id := 30000000 + i
+		systems[id] = System{
+			ID:   id,
+			Name: fmt.Sprintf("S%d", i),
+			X:    (rnd.Float64()*120 - 60) * lyInMeters,
+			Y:    (rnd.Float64()*20 - 10) * lyInMeters,
+			Z:    (rnd.Float64()*120 - 60) * lyInMeters,
+		}
+	}
+	return systems
+}
+
+// linearNeighbors — перебор всех систем, используется как эталон.
+func linearNeighbors(systems map[int]System, center System, radius float64) []int {
+	var res []int
+	for id, s := range systems {
+		if id != center.ID && Distance(center, s) <= radius {
+			res = append(res, id)
+		}
+	}
+	sort.Ints(res)
+	return res
+}
+
+func TestSpatialIndexWithin(t *testing.T) {
+	systems := universeSystems(t, 2000)
+	ix := newSpatialIndex(systems)
+	rnd := rand.New(rand.NewSource(1))
+	ids := make([]int, 0, len(systems))
+	for id := range systems {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	for _, radius := range []float64{0, 2.5, 5, 7.3, 10, 500} {
+		for i := 0; i < 50; i++ {
+			center := systems[ids[rnd.Intn(len(ids))]]
+			got := ix.within(center, radius)
+			want := linearNeighbors(systems, center, radius)
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("radius %.1f, system %s: got %d systems, want %d", radius, center.Name, len(got), len(want))
+			}
+		}
+	}
+}
+
+func benchmarkPlanner(b *testing.B) (*Planner, [][2]string) {
+	systems := universeSystems(b, 5000)
+	p, err := NewPlanner(dbstore.NewMemory(nil, nil, systems), 10)
+	if err != nil {
+		b.Fatalf("unexpected error: %v", err)
+	}
+	names := make([]string, 0, len(systems))
+	for _, s := range systems {
+		names = append(names, s.Name)
+	}
+	sort.Strings(names)
+	rnd := rand.New(rand.NewSource(3))
+	pairs := make([][2]string, 64)
+	for i := range pairs {
+		pairs[i] = [2]string{names[rnd.Intn(len(names))], names[rnd.Intn(len(names))]}
+	}
+	return p, pairs
+}
+
+// BenchmarkPlan измеряет поиск маршрута JF по полной карте.
+func BenchmarkPlan(b *testing.B) {
+	p, pairs := benchmarkPlanner(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pr := pairs[i%len(pairs)]
+		// на синтетической карте часть систем недостижима, это не ошибка
+		_, _ = p.PlanWithOptions(pr[0], pr[1], Options{Optimize: OptimizeJumpsDistance})
+	}
+}
+
+// BenchmarkNeighbors измеряет запрос по радиусу через индекс.
+func BenchmarkNeighbors(b *testing.B) {
+	p, _ := benchmarkPlanner(b)
+	list := make([]System, 0, len(p.systems))
+	for _, s := range p.systems {
+		list = append(list, s)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.index.within(list[i%len(list)], 10)
+	}
+}
+
+// BenchmarkNeighborsLinear измеряет прежний перебор всех систем для сравнения.
+func BenchmarkNeighborsLinear(b *testing.B) {
+	p, _ := benchmarkPlanner(b)
+	list := make([]System, 0, len(p.systems))
+	for _, s := range p.systems {
+		list = append(list, s)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		linearNeighbors(p.systems, list[i%len(list)], 10)
+	}
+}
@@ -0,0 +1,66 @@
+package capital
+
+// PlanSystem — система маршрута в ответе API. Координаты указаны в световых годах.
+type PlanSystem struct {
+	ID       int     `json:"id"`
+	Name     string  `json:"name"`
+	Security float64 `json:"security"`
+	RegionID int     `json:"regionId"`
+	Region   string  `json:"region"`
+	X        float64 `json:"x"`
+	Y        float64 `json:"y"`
+	Z        float64 `json:"z"`
+}
+
+// PlanLeg — один прыжок маршрута. Cumulative — расстояние от начала маршрута
+// до конца прыжка.
+type PlanLeg struct {
+	From       PlanSystem `json:"from"`
+	To         PlanSystem `json:"to"`
+	Distance   float64    `json:"distance"`
+	Cumulative float64    `json:"cumulative"`
+}
+
+// RoutePlan — маршрут капитального корабля с расстояниями и параметрами расчёта.
+// Ship, JDC и Fuel заполняются, только если расчёт выполнен для конкретного корабля.
+type RoutePlan struct {
+	Mode     Optimize      `json:"mode"`
+	Ship     *ShipProfile  `json:"ship,omitempty"`
+	JDC      *int          `json:"jdc,omitempty"`
+	Range    float64       `json:"range"`
+	Systems  []PlanSystem  `json:"systems"`
+	Legs     []PlanLeg     `json:"legs"`
+	Jumps    int           `json:"jumps"`
+	Distance float64       `json:"distance"`
+	Timeline []TimelineLeg `json:"timeline"`
+	Fuel     *Fuel         `json:"fuel,omitempty"`
+}
+
+// NewRoutePlan описывает маршрут path: системы, прыжки и суммарное расстояние.
+func NewRoutePlan(path []System) RoutePlan {
+	res := RoutePlan{
+		Systems:  make([]PlanSystem, len(path)),
+		Legs:     make([]PlanLeg, 0, max(len(path)-1, 0)),
+		Jumps:    max(len(path)-1, 0),
+		Distance: PathDistance(path),
+	}
+	for i, s := range path {
+		res.Systems[i] = PlanSystem{
+			ID:       s.ID,
+			Name:     s.Name,
+			Security: s.Security,
+			RegionID: s.RegionID,
+			Region:   s.Region,
+			X:        s.X / lyInMeters,
+			Y:        s.Y / lyInMeters,
+			Z:        s.Z / lyInMeters,
+		}
+	}
+	var cumulative float64
+	for i := 1; i < len(path); i++ {
+		d := Distance(path[i-1], path[i])
+		cumulative += d
+		res.Legs = append(res.Legs, PlanLeg{From: res.Systems[i-1], To: res.Systems[i], Distance: d, Cumulative: cumulative})
+	}
+	return res
+}
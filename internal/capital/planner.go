@@ -1,11 +1,13 @@
 package capital
 
 import (
-	"container/list"
+	"container/heap"
 	"context"
 	"errors"
+	"fmt"
 	"log"
 	"math"
+	"strings"
 
 	dbstore "github.com/tkhamez/eve-route-go/internal/dbstore"
 )
@@ -17,10 +19,12 @@ const lyInMeters = 9.4607e15
 type System = dbstore.System
 
 // Planner рассчитывает маршрут прыжков капитальных кораблей.
-// Для поиска используется BFS, соседи вычисляются по радиусу прыжка.
+// Для поиска используется алгоритм Дейкстры, соседи находятся по радиусу прыжка
+// с помощью пространственного индекса.
 type Planner struct {
 	systems   map[int]System
 	nameToID  map[string]int
+	index     *spatialIndex
 	jumpRange float64 // в световых годах
 }
 
@@ -34,12 +38,68 @@ func NewPlanner(store dbstore.Store, jumpRange float64) (*Planner, error) {
 	for id, s := range systems {
 		nameToID[s.Name] = id
 	}
-	return &Planner{systems: systems, nameToID: nameToID, jumpRange: jumpRange}, nil
+	return &Planner{systems: systems, nameToID: nameToID, index: newSpatialIndex(systems), jumpRange: jumpRange}, nil
 }
 
-// Plan ищет кратчайший маршрут между системами startName и endName.
+// JumpRange возвращает дальность прыжка, заданную при создании планировщика.
+func (p *Planner) JumpRange() float64 { return p.jumpRange }
+
+// Optimize — критерий выбора маршрута.
+type Optimize string
+
+const (
+	// OptimizeJumps — наименьшее число прыжков.
+	OptimizeJumps Optimize = "jumps"
+	// OptimizeDistance — наименьшее суммарное расстояние (и расход топлива),
+	// при равном расстоянии — наименьшее число прыжков.
+	OptimizeDistance Optimize = "distance"
+	// OptimizeJumpsDistance — наименьшее число прыжков, среди таких маршрутов —
+	// наименьшее суммарное расстояние.
+	OptimizeJumpsDistance Optimize = "jumps-distance"
+)
+
+// ParseOptimize разбирает название критерия; пустая строка означает OptimizeJumps.
+func ParseOptimize(s string) (Optimize, error) {
+	switch Optimize(s) {
+	case "":
+		return OptimizeJumps, nil
+	case OptimizeJumps, OptimizeDistance, OptimizeJumpsDistance:
+		return Optimize(s), nil
+	}
+	return "", fmt.Errorf("unknown optimize mode %q", s)
+}
+
+// Options — параметры отдельного расчёта маршрута.
+type Options struct {
+	// Range — дальность прыжка в световых годах; 0 означает дальность планировщика.
+	Range float64
+	// Optimize — критерий выбора маршрута; пустое значение означает OptimizeJumps.
+	Optimize Optimize
+	// Ship — корабль; определяет, можно ли начать маршрут в высокой безопасности.
+	Ship ShipProfile
+	// Avoid — системы и регионы, которые нельзя использовать как промежуточные точки.
+	Avoid []string
+	// Prefer — системы, в которых предпочтительно приземляться (свои структуры,
+	// NPC-станции); среди маршрутов с равной стоимостью выбирается маршрут
+	// с наибольшим числом таких промежуточных точек.
+	Prefer []string
+}
+
+// Plan ищет кратчайший маршрут между системами startName и endName
+// с дальностью прыжка, заданной при создании планировщика.
 func (p *Planner) Plan(startName, endName string) ([]System, error) {
-	log.Printf("capital planner: %s -> %s", startName, endName)
+	return p.PlanWithOptions(startName, endName, Options{})
+}
+
+// PlanWithOptions ищет кратчайший маршрут с учётом параметров расчёта.
+// Маршрут проходит только через системы, в которые можно прыгнуть (CanJumpTo);
+// для начальной системы действует отдельное правило CanJumpFrom.
+func (p *Planner) PlanWithOptions(startName, endName string, opts Options) ([]System, error) {
+	jumpRange := opts.Range
+	if jumpRange == 0 {
+		jumpRange = p.jumpRange
+	}
+	log.Printf("capital planner: %s -> %s (%.2f ly)", startName, endName, jumpRange)
 	startID, okStart := p.nameToID[startName]
 	endID, okEnd := p.nameToID[endName]
 	if !okStart || !okEnd {
@@ -48,29 +108,59 @@ func (p *Planner) Plan(startName, endName string) ([]System, error) {
 	if startID == endID {
 		return []System{p.systems[startID]}, nil
 	}
+	if err := CanJumpFrom(p.systems[startID], opts.Ship); err != nil {
+		return nil, err
+	}
+	if err := CanJumpTo(p.systems[endID]); err != nil {
+		return nil, err
+	}
+	avoid, err := p.resolve(opts.Avoid, true)
+	if err != nil {
+		return nil, err
+	}
+	prefer, err := p.resolve(opts.Prefer, false)
+	if err != nil {
+		return nil, err
+	}
 
-	visited := map[int]bool{startID: true}
+	less, err := costOrder(opts.Optimize)
+	if err != nil {
+		return nil, err
+	}
+	// Дейкстра с лексикографической стоимостью; соседи перебираются по возрастанию ID,
+	// а из равных по стоимости систем первой извлекается система с меньшим ID,
+	// поэтому среди равноценных маршрутов всегда выбирается один и тот же.
+	best := map[int]cost{startID: {}}
 	parent := map[int]int{}
-	q := list.New()
-	q.PushBack(startID)
-
+	done := map[int]bool{}
+	q := &costQueue{less: less}
+	heap.Push(q, queued{id: startID})
 	for q.Len() > 0 {
-		e := q.Front()
-		q.Remove(e)
-		cur := e.Value.(int)
-		if cur == endID {
+		cur := heap.Pop(q).(queued)
+		if done[cur.id] {
+			continue
+		}
+		done[cur.id] = true
+		if cur.id == endID {
 			break
 		}
-		for _, n := range p.neighbors(cur) {
-			if !visited[n] {
-				visited[n] = true
-				parent[n] = cur
-				q.PushBack(n)
+		for _, n := range p.neighbors(cur.id, jumpRange) {
+			if done[n] || CanJumpTo(p.systems[n]) != nil || (avoid[n] && n != endID) {
+				continue
+			}
+			c := cost{jumps: cur.cost.jumps + 1, ly: cur.cost.ly + Distance(p.systems[cur.id], p.systems[n]), other: cur.cost.other}
+			if n != endID && !prefer[n] {
+				c.other++
+			}
+			if old, ok := best[n]; !ok || less(c, old) {
+				best[n] = c
+				parent[n] = cur.id
+				heap.Push(q, queued{id: n, cost: c})
 			}
 		}
 	}
 
-	if !visited[endID] {
+	if !done[endID] {
 		return nil, errors.New("route not found")
 	}
 
@@ -84,23 +174,110 @@ func (p *Planner) Plan(startName, endName string) ([]System, error) {
 	return path, nil
 }
 
-// neighbors возвращает список систем, достижимых из указанной за один прыжок.
-func (p *Planner) neighbors(id int) []int {
-	var res []int
-	cur := p.systems[id]
-	for oid, s := range p.systems {
-		if oid == id {
+// resolve возвращает ID систем по названиям (без учёта регистра); если regions
+// равно true, название может обозначать и регион целиком.
+func (p *Planner) resolve(names []string, regions bool) (map[int]bool, error) {
+	res := map[int]bool{}
+	for _, name := range names {
+		if id, ok := p.nameToID[name]; ok {
+			res[id] = true
 			continue
 		}
-		if distance(cur, s) <= p.jumpRange {
-			res = append(res, oid)
+		found := false
+		for id, s := range p.systems {
+			if strings.EqualFold(s.Name, name) || (regions && s.Region != "" && strings.EqualFold(s.Region, name)) {
+				res[id] = true
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown system %q", name)
+		}
+	}
+	return res, nil
+}
+
+// neighbors возвращает список систем, достижимых из указанной за один прыжок,
+// упорядоченный по ID.
+func (p *Planner) neighbors(id int, jumpRange float64) []int {
+	return p.index.within(p.systems[id], jumpRange)
+}
+
+// cost — стоимость пути до системы; other — число промежуточных точек вне Options.Prefer.
+type cost struct {
+	jumps int
+	ly    float64
+	other int
+}
+
+// costOrder возвращает функцию сравнения стоимостей для критерия o.
+func costOrder(o Optimize) (func(a, b cost) bool, error) {
+	o, err := ParseOptimize(string(o))
+	if err != nil {
+		return nil, err
+	}
+	switch o {
+	case OptimizeDistance:
+		return func(a, b cost) bool {
+			if a.ly != b.ly {
+				return a.ly < b.ly
+			}
+			if a.jumps != b.jumps {
+				return a.jumps < b.jumps
+			}
+			return a.other < b.other
+		}, nil
+	case OptimizeJumpsDistance:
+		return func(a, b cost) bool {
+			if a.jumps != b.jumps {
+				return a.jumps < b.jumps
+			}
+			if a.other != b.other {
+				return a.other < b.other
+			}
+			return a.ly < b.ly
+		}, nil
+	}
+	return func(a, b cost) bool {
+		if a.jumps != b.jumps {
+			return a.jumps < b.jumps
 		}
+		return a.other < b.other
+	}, nil
+}
+
+type queued struct {
+	id   int
+	cost cost
+}
+
+// costQueue — очередь с приоритетом для алгоритма Дейкстры.
+type costQueue struct {
+	items []queued
+	less  func(a, b cost) bool
+}
+
+func (q *costQueue) Len() int { return len(q.items) }
+func (q *costQueue) Less(i, j int) bool {
+	a, b := q.items[i], q.items[j]
+	if q.less(a.cost, b.cost) {
+		return true
+	}
+	if q.less(b.cost, a.cost) {
+		return false
 	}
-	return res
+	return a.id < b.id
+}
+func (q *costQueue) Swap(i, j int) { q.items[i], q.items[j] = q.items[j], q.items[i] }
+func (q *costQueue) Push(x any)    { q.items = append(q.items, x.(queued)) }
+func (q *costQueue) Pop() any {
+	it := q.items[len(q.items)-1]
+	q.items = q.items[:len(q.items)-1]
+	return it
 }
 
-// distance вычисляет расстояние между двумя системами в световых годах.
-func distance(a, b System) float64 {
+// Distance вычисляет расстояние между двумя системами в световых годах.
+func Distance(a, b System) float64 {
 	dx := a.X - b.X
 	dy := a.Y - b.Y
 	dz := a.Z - b.Z
@@ -116,7 +293,7 @@ func PathDistance(path []System) float64 {
 	log.Printf("capital planner: calculating distance for %d jumps", len(path)-1)
 	var total float64
 	for i := 1; i < len(path); i++ {
-		total += distance(path[i-1], path[i])
+		total += Distance(path[i-1], path[i])
 	}
 	return total
 }
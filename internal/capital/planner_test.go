@@ -2,9 +2,11 @@ package capital
 
 import (
 	"math"
+	"strings"
 	"testing"
 
 	dbstore "github.com/tkhamez/eve-route-go/internal/dbstore"
+	"github.com/tkhamez/eve-route-go/internal/graph"
 )
 
 func TestPlan(t *testing.T) {
@@ -37,9 +39,144 @@ func TestPathDistance(t *testing.T) {
 	total := PathDistance(path)
 	var expected float64
 	for i := 1; i < len(path); i++ {
-		expected += distance(path[i-1], path[i])
+		expected += Distance(path[i-1], path[i])
 	}
 	if math.Abs(total-expected) > 1e-9 {
 		t.Fatalf("unexpected distance: %.6f != %.6f", total, expected)
 	}
 }
+
+func TestSystemsFromGraph(t *testing.T) {
+	g := graph.Graph{Systems: []graph.System{
+		{ID: 1, Name: "Known", X: lyInMeters, Z: -lyInMeters},
+		{ID: 2, Name: "Unknown"},
+	}}
+	systems := SystemsFromGraph(g)
+	if len(systems) != 1 {
+		t.Fatalf("expected 1 system, got %d", len(systems))
+	}
+	if s := systems[1]; s.Name != "Known" || s.X != lyInMeters || s.Z != -lyInMeters {
+		t.Fatalf("unexpected system: %+v", s)
+	}
+}
+
+func TestPlanWithOptionsRange(t *testing.T) {
+	p, err := NewPlanner(dbstore.NewMemory(nil, nil, DefaultSystems()), 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// дальности суперкарриера без JDC не хватает даже на первый прыжок
+	if _, err := p.PlanWithOptions("Maila", "Todifrauan", Options{Range: 3}); err == nil {
+		t.Fatal("expected route not found")
+	}
+	path, err := p.PlanWithOptions("Maila", "Todifrauan", Options{Range: 10})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(path) >= 4 {
+		t.Fatalf("expected a shorter route with 10 ly range, got %d systems", len(path))
+	}
+}
+
+func TestPlanOptimize(t *testing.T) {
+	ly := func(x, y float64) (float64, float64) { return x * lyInMeters, y * lyInMeters }
+	systems := map[int]System{}
+	for i, s := range []struct {
+		name string
+		x, y float64
+	}{{"A", 0, 0}, {"M1", 6, 3}, {"M2", 6, -1}, {"N1", 4, 0}, {"N2", 8, 0}, {"E", 12, 0}} {
+		x, y := ly(s.x, s.y)
+		systems[i+1] = System{ID: i + 1, Name: s.name, X: x, Y: y}
+	}
+	p, err := NewPlanner(dbstore.NewMemory(nil, nil, systems), 7)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, tc := range []struct {
+		optimize Optimize
+		want     string
+	}{
+		// из двух маршрутов в два прыжка выбирается система с меньшим ID
+		{"", "A M1 E"},
+		{OptimizeJumps, "A M1 E"},
+		{OptimizeJumpsDistance, "A M2 E"},
+		{OptimizeDistance, "A N1 N2 E"},
+	} {
+		for i := 0; i < 20; i++ {
+			path, err := p.PlanWithOptions("A", "E", Options{Optimize: tc.optimize})
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", tc.optimize, err)
+			}
+			var names []string
+			for _, s := range path {
+				names = append(names, s.Name)
+			}
+			if got := strings.Join(names, " "); got != tc.want {
+				t.Fatalf("%s: got %s, want %s", tc.optimize, got, tc.want)
+			}
+		}
+	}
+	if _, err := p.PlanWithOptions("A", "E", Options{Optimize: "fuel"}); err == nil {
+		t.Fatal("expected error for unknown optimize mode")
+	}
+}
+
+func TestPlanAvoidPrefer(t *testing.T) {
+	systems := map[int]System{}
+	for i, s := range []struct {
+		name, region string
+		x, y         float64
+	}{{"A", "West", 0, 0}, {"M1", "Hostile", 6, 3}, {"M2", "West", 6, -1}, {"N1", "West", 4, 0}, {"N2", "West", 8, 0}, {"E", "East", 12, 0}} {
+		systems[i+1] = System{ID: i + 1, Name: s.name, Region: s.region, X: s.x * lyInMeters, Y: s.y * lyInMeters}
+	}
+	p, err := NewPlanner(dbstore.NewMemory(nil, nil, systems), 7)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, tc := range []struct {
+		opts Options
+		want string
+	}{
+		{Options{Prefer: []string{"m2"}}, "A M2 E"},
+		{Options{Avoid: []string{"M1"}}, "A M2 E"},
+		{Options{Avoid: []string{"hostile", "M2"}}, "A N1 N2 E"},
+		// начальная и конечная системы не считаются промежуточными точками
+		{Options{Avoid: []string{"A", "East"}}, "A M1 E"},
+		{Options{Optimize: OptimizeDistance, Prefer: []string{"M2"}}, "A N1 N2 E"},
+	} {
+		path, err := p.PlanWithOptions("A", "E", tc.opts)
+		if err != nil {
+			t.Fatalf("%+v: unexpected error: %v", tc.opts, err)
+		}
+		var names []string
+		for _, s := range path {
+			names = append(names, s.Name)
+		}
+		if got := strings.Join(names, " "); got != tc.want {
+			t.Errorf("%+v: got %s, want %s", tc.opts, got, tc.want)
+		}
+	}
+	if _, err := p.PlanWithOptions("A", "E", Options{Avoid: []string{"Nowhere"}}); err == nil {
+		t.Error("expected error for unknown avoid entry")
+	}
+	if _, err := p.PlanWithOptions("A", "E", Options{Prefer: []string{"West"}}); err == nil {
+		t.Error("expected error for region in prefer")
+	}
+}
+
+func TestNewRoutePlan(t *testing.T) {
+	path := line(3, 4)
+	path[2].Region = "Region"
+	plan := NewRoutePlan(path)
+	if plan.Jumps != 2 || len(plan.Systems) != 3 || len(plan.Legs) != 2 || math.Abs(plan.Distance-7) > 1e-9 {
+		t.Fatalf("unexpected plan: %+v", plan)
+	}
+	last := plan.Legs[1]
+	if last.From.Name != "S1" || last.To.Region != "Region" || math.Abs(last.Distance-4) > 1e-9 ||
+		math.Abs(last.Cumulative-7) > 1e-9 || math.Abs(last.To.X-7) > 1e-9 {
+		t.Fatalf("unexpected leg: %+v", last)
+	}
+	if plan := NewRoutePlan(path[:1]); plan.Jumps != 0 || len(plan.Legs) != 0 {
+		t.Fatalf("unexpected single-system plan: %+v", plan)
+	}
+}
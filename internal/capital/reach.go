@@ -0,0 +1,56 @@
+package capital
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Reachable — система в пределах одного прыжка.
+type Reachable struct {
+	ID       int     `json:"id"`
+	Name     string  `json:"name"`
+	Security float64 `json:"security"`
+	RegionID int     `json:"regionId"`
+	Region   string  `json:"region"`
+	Distance float64 `json:"distance"`
+}
+
+// InRange возвращает системы, в которые корабль ship может прыгнуть из системы name
+// с дальностью jumpRange, упорядоченные по расстоянию. Если reverse равно true,
+// возвращаются системы, из которых можно прыгнуть в name. Правила CanJumpFrom
+// и CanJumpTo применяются к отправлению и назначению соответственно.
+func (p *Planner) InRange(name string, jumpRange float64, ship ShipProfile, reverse bool) ([]Reachable, error) {
+	if jumpRange <= 0 {
+		return nil, fmt.Errorf("invalid range %.2f", jumpRange)
+	}
+	id, ok := p.nameToID[name]
+	if !ok {
+		return nil, errors.New("system not found")
+	}
+	center := p.systems[id]
+	if reverse {
+		if err := CanJumpTo(center); err != nil {
+			return nil, err
+		}
+	} else if err := CanJumpFrom(center, ship); err != nil {
+		return nil, err
+	}
+	res := []Reachable{}
+	for _, nid := range p.neighbors(id, jumpRange) {
+		s := p.systems[nid]
+		if reverse && CanJumpFrom(s, ship) != nil || !reverse && CanJumpTo(s) != nil {
+			continue
+		}
+		res = append(res, Reachable{
+			ID:       s.ID,
+			Name:     s.Name,
+			Security: s.Security,
+			RegionID: s.RegionID,
+			Region:   s.Region,
+			Distance: Distance(center, s),
+		})
+	}
+	sort.SliceStable(res, func(i, j int) bool { return res[i].Distance < res[j].Distance })
+	return res, nil
+}
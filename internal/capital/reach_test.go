@@ -0,0 +1,58 @@
+package capital
+
+import (
+	"testing"
+
+	dbstore "github.com/tkhamez/eve-route-go/internal/dbstore"
+)
+
+func TestInRange(t *testing.T) {
+	systems := map[int]System{}
+	for i, s := range []struct {
+		name     string
+		x        float64
+		security float64
+	}{{"Center", 0, 0.2}, {"Near", 2, 0.1}, {"High", 3, 0.7}, {"Mid", -4, 0.4}, {"Far", 9, -0.3}} {
+		systems[i+1] = System{ID: i + 1, Name: s.name, X: s.x * lyInMeters, Security: s.security, RegionID: 7, Region: "Region"}
+	}
+	p, err := NewPlanner(dbstore.NewMemory(nil, nil, systems), 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	carrier, _ := Ship("carrier")
+	jf, _ := Ship("jf")
+
+	names := func(list []Reachable) []string {
+		var res []string
+		for _, r := range list {
+			res = append(res, r.Name)
+		}
+		return res
+	}
+	// в высокую безопасность прыгнуть нельзя
+	got, err := p.InRange("Center", 5, carrier, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := names(got); len(n) != 2 || n[0] != "Near" || n[1] != "Mid" || got[1].Distance != 4 || got[0].Region != "Region" {
+		t.Fatalf("unexpected systems: %+v", got)
+	}
+	// из высокой безопасности может прыгнуть только JF
+	got, err = p.InRange("Center", 5, jf, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := names(got); len(n) != 3 || n[1] != "High" {
+		t.Fatalf("unexpected reverse systems: %+v", got)
+	}
+	got, _ = p.InRange("Center", 5, carrier, true)
+	if len(got) != 2 {
+		t.Fatalf("unexpected reverse systems for carrier: %+v", got)
+	}
+	if _, err := p.InRange("High", 5, carrier, true); err == nil {
+		t.Error("expected error for highsec target")
+	}
+	if _, err := p.InRange("Nowhere", 5, carrier, false); err == nil {
+		t.Error("expected error for unknown system")
+	}
+}
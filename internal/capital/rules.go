@@ -0,0 +1,60 @@
+package capital
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	// regionPochven — регион Pochven, где прыжковые двигатели не работают.
+	regionPochven = 10000070
+	// systemZarzakh — система Zarzakh, где нельзя зажечь цино.
+	systemZarzakh = 30100000
+	// firstWormholeID — ID систем червоточин (и Abyssal) начинаются с этого значения.
+	firstWormholeID = 31000000
+)
+
+// Highsec сообщает, относится ли система к высокому уровню безопасности:
+// в игре безопасность округляется до десятых, поэтому граница — 0,45.
+func Highsec(s System) bool {
+	return math.Round(s.Security*10)/10 >= 0.5
+}
+
+// restricted возвращает причину, по которой в системе не работают прыжковые
+// двигатели и цино, или пустую строку.
+func restricted(s System) string {
+	switch {
+	case s.ID >= firstWormholeID:
+		return "wormhole space"
+	case s.RegionID == regionPochven:
+		return "Pochven"
+	case s.ID == systemZarzakh:
+		return "Zarzakh"
+	}
+	return ""
+}
+
+// CanJumpTo проверяет, можно ли прыгнуть в систему: нельзя в высокую
+// безопасность, Pochven, Zarzakh и пространство червоточин.
+func CanJumpTo(s System) error {
+	if reason := restricted(s); reason != "" {
+		return fmt.Errorf("cannot jump into %s: %s", s.Name, reason)
+	}
+	if Highsec(s) {
+		return fmt.Errorf("cannot jump into %s: high security space", s.Name)
+	}
+	return nil
+}
+
+// CanJumpFrom проверяет, можно ли начать прыжок из системы. Из высокой
+// безопасности могут прыгать только корабли с ShipProfile.JumpFromHighsec
+// (Jump Freighter и Black Ops); из Pochven, Zarzakh и червоточин — никто.
+func CanJumpFrom(s System, ship ShipProfile) error {
+	if reason := restricted(s); reason != "" {
+		return fmt.Errorf("cannot jump out of %s: %s", s.Name, reason)
+	}
+	if Highsec(s) && !ship.JumpFromHighsec {
+		return fmt.Errorf("cannot jump out of %s: high security space", s.Name)
+	}
+	return nil
+}
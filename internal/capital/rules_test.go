@@ -0,0 +1,81 @@
+package capital
+
+import (
+	"strings"
+	"testing"
+
+	dbstore "github.com/tkhamez/eve-route-go/internal/dbstore"
+)
+
+func TestJumpRules(t *testing.T) {
+	jf, _ := Ship("jf")
+	blackOps, _ := Ship("blackops")
+	carrier, _ := Ship("carrier")
+	for _, tc := range []struct {
+		system    System
+		to        bool
+		fromJF    bool
+		fromBLOPS bool
+		fromOther bool
+	}{
+		{System{ID: 1, Name: "Low", Security: 0.44}, true, true, true, true},
+		{System{ID: 2, Name: "Null", Security: -0.5}, true, true, true, true},
+		{System{ID: 3, Name: "High", Security: 0.45}, false, true, true, false},
+		{System{ID: 4, Name: "Pochven", Security: -1, RegionID: regionPochven}, false, false, false, false},
+		{System{ID: systemZarzakh, Name: "Zarzakh", Security: -1}, false, false, false, false},
+		{System{ID: 31000005, Name: "Thera", Security: -0.99}, false, false, false, false},
+	} {
+		if got := CanJumpTo(tc.system) == nil; got != tc.to {
+			t.Errorf("%s: CanJumpTo = %v, want %v", tc.system.Name, got, tc.to)
+		}
+		if got := CanJumpFrom(tc.system, jf) == nil; got != tc.fromJF {
+			t.Errorf("%s: CanJumpFrom(jf) = %v, want %v", tc.system.Name, got, tc.fromJF)
+		}
+		if got := CanJumpFrom(tc.system, blackOps) == nil; got != tc.fromBLOPS {
+			t.Errorf("%s: CanJumpFrom(blackops) = %v, want %v", tc.system.Name, got, tc.fromBLOPS)
+		}
+		if got := CanJumpFrom(tc.system, carrier) == nil; got != tc.fromOther {
+			t.Errorf("%s: CanJumpFrom(carrier) = %v, want %v", tc.system.Name, got, tc.fromOther)
+		}
+	}
+}
+
+func TestPlanAvoidsRestrictedSpace(t *testing.T) {
+	systems := map[int]System{}
+	for i, s := range []struct {
+		name     string
+		x, y     float64
+		security float64
+	}{{"Jita", 0, 0, 0.95}, {"Perimeter", 4, 0, 0.9}, {"Tama", 4, 3, 0.3}, {"Nourvukaiken", 8, 0, 0.1}} {
+		systems[i+1] = System{ID: i + 1, Name: s.name, X: s.x * lyInMeters, Y: s.y * lyInMeters, Security: s.security}
+	}
+	p, err := NewPlanner(dbstore.NewMemory(nil, nil, systems), 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	jf, _ := Ship("jf")
+	path, err := p.PlanWithOptions("Jita", "Nourvukaiken", Options{Ship: jf})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// более короткий путь через Perimeter проходит через высокую безопасность
+	if len(path) != 3 || path[1].Name != "Tama" {
+		t.Fatalf("unexpected path: %v", path)
+	}
+	carrier, _ := Ship("carrier")
+	if _, err := p.PlanWithOptions("Jita", "Nourvukaiken", Options{Ship: carrier}); err == nil || !strings.Contains(err.Error(), "out of Jita") {
+		t.Fatalf("expected origin error, got %v", err)
+	}
+	if _, err := p.PlanWithOptions("Nourvukaiken", "Perimeter", Options{Ship: jf}); err == nil || !strings.Contains(err.Error(), "into Perimeter") {
+		t.Fatalf("expected destination error, got %v", err)
+	}
+	// Black Ops прыгает из высокой безопасности, но не в неё
+	blackOps, _ := Ship("blackops")
+	path, err = p.PlanWithOptions("Jita", "Nourvukaiken", Options{Ship: blackOps, Range: 5})
+	if err != nil || len(path) != 3 || path[1].Name != "Tama" {
+		t.Fatalf("unexpected Black Ops path: %v, %v", path, err)
+	}
+	if _, err := p.PlanWithOptions("Tama", "Perimeter", Options{Ship: blackOps, Range: 5}); err == nil || !strings.Contains(err.Error(), "into Perimeter") {
+		t.Fatalf("expected Black Ops destination error, got %v", err)
+	}
+}
@@ -0,0 +1,85 @@
+package capital
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ShipClass — класс корабля с прыжковым двигателем.
+type ShipClass string
+
+const (
+	ShipJumpFreighter ShipClass = "jf"
+	ShipCarrier       ShipClass = "carrier"
+	ShipDreadnought   ShipClass = "dread"
+	ShipFAX           ShipClass = "fax"
+	ShipSupercarrier  ShipClass = "super"
+	ShipTitan         ShipClass = "titan"
+	ShipRorqual       ShipClass = "rorqual"
+	ShipBlackOps      ShipClass = "blackops"
+)
+
+const (
+	// MaxJDC — максимальный уровень навыка Jump Drive Calibration.
+	MaxJDC = 5
+	// jdcBonus — прибавка к базовой дальности за каждый уровень JDC.
+	jdcBonus = 0.2
+)
+
+// ShipProfile описывает прыжковые характеристики класса кораблей.
+type ShipProfile struct {
+	Class ShipClass `json:"class"`
+	Name  string    `json:"name"`
+	// BaseRange — дальность прыжка без навыка JDC в световых годах.
+	BaseRange float64 `json:"baseRange"`
+	// FatigueBonus — доля, на которую уменьшается расстояние при расчёте усталости.
+	FatigueBonus float64 `json:"fatigueBonus"`
+	// FuelPerLY — расход изотопов на световой год без навыков.
+	FuelPerLY float64 `json:"fuelPerLy"`
+	// HullFuelBonus — уменьшение расхода топлива за уровень навыка корпуса.
+	HullFuelBonus float64 `json:"hullFuelBonus"`
+	// JumpFromHighsec — корабль может находиться в высокой безопасности и прыгать
+	// оттуда (Jump Freighter и Black Ops); прыгнуть в высокую безопасность не может никто.
+	JumpFromHighsec bool `json:"jumpFromHighsec"`
+}
+
+// shipProfiles — известные классы кораблей.
+var shipProfiles = map[ShipClass]ShipProfile{
+	ShipJumpFreighter: {Class: ShipJumpFreighter, Name: "Jump Freighter", BaseRange: 5, FatigueBonus: 0.9, FuelPerLY: 10000, HullFuelBonus: 0.1, JumpFromHighsec: true},
+	ShipCarrier:       {Class: ShipCarrier, Name: "Carrier", BaseRange: 3.5, FuelPerLY: 3000},
+	ShipDreadnought:   {Class: ShipDreadnought, Name: "Dreadnought", BaseRange: 3.5, FuelPerLY: 3000},
+	ShipFAX:           {Class: ShipFAX, Name: "Force Auxiliary", BaseRange: 3.5, FuelPerLY: 3000},
+	ShipSupercarrier:  {Class: ShipSupercarrier, Name: "Supercarrier", BaseRange: 3, FuelPerLY: 3000},
+	ShipTitan:         {Class: ShipTitan, Name: "Titan", BaseRange: 3, FuelPerLY: 3000},
+	ShipRorqual:       {Class: ShipRorqual, Name: "Rorqual", BaseRange: 5, FatigueBonus: 0.9, FuelPerLY: 4000},
+	ShipBlackOps:      {Class: ShipBlackOps, Name: "Black Ops", BaseRange: 4, FatigueBonus: 0.75, FuelPerLY: 700, JumpFromHighsec: true},
+}
+
+// Ship возвращает профиль класса кораблей (без учёта регистра).
+func Ship(class string) (ShipProfile, error) {
+	p, ok := shipProfiles[ShipClass(strings.ToLower(class))]
+	if !ok {
+		return ShipProfile{}, fmt.Errorf("unknown ship class %q", class)
+	}
+	return p, nil
+}
+
+// Ships возвращает все профили, упорядоченные по классу.
+func Ships() []ShipProfile {
+	res := make([]ShipProfile, 0, len(shipProfiles))
+	for _, p := range shipProfiles {
+		res = append(res, p)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Class < res[j].Class })
+	return res
+}
+
+// Range возвращает дальность прыжка при уровне навыка Jump Drive Calibration jdc:
+// каждый уровень увеличивает базовую дальность на 20%.
+func (p ShipProfile) Range(jdc int) (float64, error) {
+	if jdc < 0 || jdc > MaxJDC {
+		return 0, fmt.Errorf("invalid JDC level %d", jdc)
+	}
+	return p.BaseRange * (1 + jdcBonus*float64(jdc)), nil
+}
@@ -0,0 +1,40 @@
+package capital
+
+import (
+	"math"
+	"testing"
+)
+
+func TestShipRange(t *testing.T) {
+	cases := []struct {
+		class string
+		jdc   int
+		want  float64
+	}{
+		{"jf", 0, 5},
+		{"JF", 5, 10},
+		{"carrier", 4, 6.3},
+		{"titan", 5, 6},
+		{"blackops", 5, 8},
+	}
+	for _, c := range cases {
+		p, err := Ship(c.class)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", c.class, err)
+		}
+		got, err := p.Range(c.jdc)
+		if err != nil || math.Abs(got-c.want) > 1e-9 {
+			t.Errorf("%s JDC %d: got %.2f (%v), want %.2f", c.class, c.jdc, got, err, c.want)
+		}
+	}
+	if _, err := Ship("shuttle"); err == nil {
+		t.Error("expected error for unknown class")
+	}
+	p, _ := Ship("dread")
+	if _, err := p.Range(6); err == nil {
+		t.Error("expected error for JDC 6")
+	}
+	if len(Ships()) != 8 {
+		t.Errorf("expected 8 ship classes, got %d", len(Ships()))
+	}
+}
@@ -3,24 +3,71 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	// defaultRouteTimeout — ограничение времени поиска маршрута по умолчанию.
+	defaultRouteTimeout = 5 * time.Second
+	// defaultRouteCacheSize — количество маршрутов в кэше по умолчанию.
+	defaultRouteCacheSize = 1024
 )
 
 // Config содержит настройки приложения.
 type Config struct {
-	DatabaseURL string
-	Port        string
+	DatabaseURL    string
+	Port           string
+	RouteTimeout   time.Duration
+	RouteCacheSize int
+	// GraphFile — путь к JSON-файлу графа карты; пустое значение означает встроенный граф.
+	GraphFile string
+	// GraphStrict запрещает запуск сервера с графом, в котором найдены ошибки целостности.
+	GraphStrict bool
 }
 
 // FromEnv читает переменные окружения и возвращает Config.
 // Если переменная PORT не задана, используется значение по умолчанию 8080.
+// ROUTE_TIMEOUT задаётся в формате time.ParseDuration, по умолчанию 5s.
+// ROUTE_CACHE_SIZE — количество маршрутов в кэше, по умолчанию 1024.
+// GRAPH_FILE — граф карты, созданный командами импорта.
+// GRAPH_STRICT=true включает строгую проверку графа при запуске.
 func FromEnv() Config {
 	cfg := Config{
-		DatabaseURL: os.Getenv("DATABASE_URL"),
-		Port:        os.Getenv("PORT"),
+		DatabaseURL:    os.Getenv("DATABASE_URL"),
+		Port:           os.Getenv("PORT"),
+		RouteTimeout:   defaultRouteTimeout,
+		RouteCacheSize: defaultRouteCacheSize,
+		GraphFile:      os.Getenv("GRAPH_FILE"),
 	}
 	if cfg.Port == "" {
 		cfg.Port = "8080"
 	}
-	log.Printf("config loaded: port=%s", cfg.Port)
+	if v := os.Getenv("ROUTE_TIMEOUT"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			log.Printf("invalid ROUTE_TIMEOUT %q, using %s", v, defaultRouteTimeout)
+		} else {
+			cfg.RouteTimeout = d
+		}
+	}
+	if v := os.Getenv("GRAPH_STRICT"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			log.Printf("invalid GRAPH_STRICT %q, using false", v)
+		} else {
+			cfg.GraphStrict = b
+		}
+	}
+	if v := os.Getenv("ROUTE_CACHE_SIZE"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			log.Printf("invalid ROUTE_CACHE_SIZE %q, using %d", v, defaultRouteCacheSize)
+		} else {
+			cfg.RouteCacheSize = n
+		}
+	}
+	log.Printf("config loaded: port=%s route_timeout=%s route_cache_size=%d graph_file=%q graph_strict=%t",
+		cfg.Port, cfg.RouteTimeout, cfg.RouteCacheSize, cfg.GraphFile, cfg.GraphStrict)
 	return cfg
 }
@@ -28,3 +28,17 @@ func StoreGraph(_ context.Context, path string, g graph.Graph) error {
 	log.Printf("db: stored graph with %d systems to %s", len(g.Systems), path)
 	return nil
 }
+
+// LoadGraph читает граф из JSON-файла, сохранённого StoreGraph.
+func LoadGraph(_ context.Context, path string) (graph.Graph, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return graph.Graph{}, err
+	}
+	var g graph.Graph
+	if err := json.Unmarshal(data, &g); err != nil {
+		return graph.Graph{}, err
+	}
+	log.Printf("db: loaded graph with %d systems from %s", len(g.Systems), path)
+	return g, nil
+}
@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"database/sql"
+	"log"
 
 	_ "modernc.org/sqlite"
 )
@@ -12,9 +13,36 @@ type SQLite struct {
 	db *sql.DB
 }
 
-// NewSQLite creates a new SQLite store.
-func NewSQLite(db *sql.DB) *SQLite {
-	return &SQLite{db: db}
+// NewSQLite creates a new SQLite store and ensures the schema of the
+// route settings tables. It fails if the tables cannot be created.
+func NewSQLite(db *sql.DB) (*SQLite, error) {
+	s := &SQLite{db: db}
+	if err := s.init(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *SQLite) init() error {
+	for _, q := range []string{
+		`CREATE TABLE IF NOT EXISTS avoided_systems (
+        character_id INTEGER NOT NULL,
+        system_id INTEGER NOT NULL,
+        PRIMARY KEY (character_id, system_id)
+    )`,
+		`CREATE TABLE IF NOT EXISTS removed_connections (
+        character_id INTEGER NOT NULL,
+        system1_id INTEGER NOT NULL,
+        system2_id INTEGER NOT NULL,
+        PRIMARY KEY (character_id, system1_id, system2_id)
+    )`,
+	} {
+		if _, err := s.db.Exec(q); err != nil {
+			log.Println("create table:", err)
+			return err
+		}
+	}
+	return nil
 }
 
 // Ansiblexes loads Ansiblex gates from SQLite.
@@ -36,8 +64,19 @@ func (s *SQLite) Ansiblexes(ctx context.Context) ([]Ansiblex, error) {
 }
 
 // TemporaryConnections loads temporary connections from SQLite.
+// Databases without the expires column are supported, their connections
+// have no expiry time.
 func (s *SQLite) TemporaryConnections(ctx context.Context) ([]TemporaryConnection, error) {
-	rows, err := s.db.QueryContext(ctx, "SELECT system1_id, system2_id FROM temporary_connections")
+	var hasExpires bool
+	err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) > 0 FROM pragma_table_info('temporary_connections') WHERE name = 'expires'").Scan(&hasExpires)
+	if err != nil {
+		return nil, err
+	}
+	query := "SELECT system1_id, system2_id, NULL FROM temporary_connections"
+	if hasExpires {
+		query = "SELECT system1_id, system2_id, expires FROM temporary_connections"
+	}
+	rows, err := s.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -45,7 +84,7 @@ func (s *SQLite) TemporaryConnections(ctx context.Context) ([]TemporaryConnectio
 	var res []TemporaryConnection
 	for rows.Next() {
 		var c TemporaryConnection
-		if err := rows.Scan(&c.System1ID, &c.System2ID); err != nil {
+		if err := rows.Scan(&c.System1ID, &c.System2ID, &c.Expires); err != nil {
 			return nil, err
 		}
 		res = append(res, c)
@@ -53,9 +92,24 @@ func (s *SQLite) TemporaryConnections(ctx context.Context) ([]TemporaryConnectio
 	return res, rows.Err()
 }
 
-// Systems loads capital systems from SQLite.
+// Systems loads capital systems from SQLite. The security, region_id and
+// region columns are optional; without them the values are zero.
 func (s *SQLite) Systems(ctx context.Context) (map[int]System, error) {
-	rows, err := s.db.QueryContext(ctx, "SELECT id, name, x, y, z FROM systems")
+	query := "SELECT id, name, x, y, z"
+	for _, c := range []struct{ name, zero string }{{"security", "0"}, {"region_id", "0"}, {"region", "''"}} {
+		var ok bool
+		err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) > 0 FROM pragma_table_info('systems') WHERE name = ?", c.name).Scan(&ok)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			query += ", COALESCE(" + c.name + ", " + c.zero + ")"
+		} else {
+			log.Printf("systems table has no %s column", c.name)
+			query += ", " + c.zero
+		}
+	}
+	rows, err := s.db.QueryContext(ctx, query+" FROM systems")
 	if err != nil {
 		return nil, err
 	}
@@ -63,10 +117,74 @@ func (s *SQLite) Systems(ctx context.Context) (map[int]System, error) {
 	systems := make(map[int]System)
 	for rows.Next() {
 		var sys System
-		if err := rows.Scan(&sys.ID, &sys.Name, &sys.X, &sys.Y, &sys.Z); err != nil {
+		if err := rows.Scan(&sys.ID, &sys.Name, &sys.X, &sys.Y, &sys.Z, &sys.Security, &sys.RegionID, &sys.Region); err != nil {
 			return nil, err
 		}
 		systems[sys.ID] = sys
 	}
 	return systems, rows.Err()
 }
+
+// AvoidedSystems loads the systems avoided by the character from SQLite.
+func (s *SQLite) AvoidedSystems(ctx context.Context, characterID int) ([]int, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT system_id FROM avoided_systems WHERE character_id = ? ORDER BY system_id", characterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		res = append(res, id)
+	}
+	return res, rows.Err()
+}
+
+// AddAvoidedSystem stores an avoided system of the character in SQLite.
+func (s *SQLite) AddAvoidedSystem(ctx context.Context, characterID, systemID int) error {
+	_, err := s.db.ExecContext(ctx, "INSERT INTO avoided_systems (character_id, system_id) VALUES (?, ?) ON CONFLICT DO NOTHING", characterID, systemID)
+	return err
+}
+
+// RemovedConnections loads the connections removed by the character from SQLite.
+func (s *SQLite) RemovedConnections(ctx context.Context, characterID int) ([]RemovedConnection, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT system1_id, system2_id FROM removed_connections WHERE character_id = ? ORDER BY system1_id, system2_id", characterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := []RemovedConnection{}
+	for rows.Next() {
+		var c RemovedConnection
+		if err := rows.Scan(&c.System1ID, &c.System2ID); err != nil {
+			return nil, err
+		}
+		res = append(res, c)
+	}
+	return res, rows.Err()
+}
+
+// AddRemovedConnection stores a removed connection of the character in SQLite.
+func (s *SQLite) AddRemovedConnection(ctx context.Context, characterID int, c RemovedConnection) error {
+	_, err := s.db.ExecContext(ctx, "INSERT INTO removed_connections (character_id, system1_id, system2_id) VALUES (?, ?, ?) ON CONFLICT DO NOTHING", characterID, c.System1ID, c.System2ID)
+	return err
+}
+
+// ResetRoute deletes all avoided systems and removed connections of the character from SQLite.
+func (s *SQLite) ResetRoute(ctx context.Context, characterID int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, "DELETE FROM avoided_systems WHERE character_id = ?", characterID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM removed_connections WHERE character_id = ?", characterID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,218 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/tkhamez/eve-route-go/internal/capital"
+)
+
+// TestSQLiteUserRoute проверяет хранение настроек маршрута персонажей.
+func TestSQLiteUserRoute(t *testing.T) {
+	conn, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+	ctx := context.Background()
+	// таблицы настроек маршрута создаёт NewSQLite
+	s, err := NewSQLite(conn)
+	if err != nil {
+		t.Fatalf("NewSQLite() error = %v", err)
+	}
+	for _, id := range []int{3, 1, 3} {
+		if err := s.AddAvoidedSystem(ctx, 7, id); err != nil {
+			t.Fatalf("AddAvoidedSystem() error = %v", err)
+		}
+	}
+	for _, c := range []RemovedConnection{{System1ID: 2, System2ID: 3}, {System1ID: 1, System2ID: 2}, {System1ID: 2, System2ID: 3}} {
+		if err := s.AddRemovedConnection(ctx, 7, c); err != nil {
+			t.Fatalf("AddRemovedConnection() error = %v", err)
+		}
+	}
+	if err := s.AddAvoidedSystem(ctx, 8, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	avoided, err := s.AvoidedSystems(ctx, 7)
+	if err != nil || !reflect.DeepEqual(avoided, []int{1, 3}) {
+		t.Fatalf("AvoidedSystems() = %v, %v", avoided, err)
+	}
+	removed, err := s.RemovedConnections(ctx, 7)
+	if err != nil || !reflect.DeepEqual(removed, []RemovedConnection{{System1ID: 1, System2ID: 2}, {System1ID: 2, System2ID: 3}}) {
+		t.Fatalf("RemovedConnections() = %v, %v", removed, err)
+	}
+
+	if err := s.ResetRoute(ctx, 7); err != nil {
+		t.Fatalf("ResetRoute() error = %v", err)
+	}
+	if avoided, _ := s.AvoidedSystems(ctx, 7); len(avoided) != 0 {
+		t.Fatalf("expected no avoided systems after reset, got %v", avoided)
+	}
+	if removed, _ := s.RemovedConnections(ctx, 7); len(removed) != 0 {
+		t.Fatalf("expected no removed connections after reset, got %v", removed)
+	}
+	if avoided, _ := s.AvoidedSystems(ctx, 8); !reflect.DeepEqual(avoided, []int{2}) {
+		t.Fatalf("reset must not touch other characters, got %v", avoided)
+	}
+}
+
+func TestNewSQLiteError(t *testing.T) {
+	conn, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+	if _, err := NewSQLite(conn); err == nil {
+		t.Fatal("expected error when the tables cannot be created")
+	}
+}
+
+// TestSQLiteTemporaryConnections проверяет чтение временных соединений из баз
+// со столбцом expires и без него.
+func TestSQLiteTemporaryConnections(t *testing.T) {
+	expires := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		name   string
+		schema []string
+		want   []TemporaryConnection
+	}{
+		{
+			name: "without expires",
+			schema: []string{
+				"CREATE TABLE temporary_connections (system1_id INTEGER, system2_id INTEGER)",
+				"INSERT INTO temporary_connections VALUES (1, 2)",
+			},
+			want: []TemporaryConnection{{System1ID: 1, System2ID: 2}},
+		},
+		{
+			name: "with expires",
+			schema: []string{
+				"CREATE TABLE temporary_connections (system1_id INTEGER, system2_id INTEGER, expires DATETIME)",
+				"INSERT INTO temporary_connections VALUES (1, 2, '2024-05-01 12:00:00'), (2, 3, NULL)",
+			},
+			want: []TemporaryConnection{{System1ID: 1, System2ID: 2, Expires: &expires}, {System1ID: 2, System2ID: 3}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			conn, err := sql.Open("sqlite", ":memory:")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			conn.SetMaxOpenConns(1)
+			for _, q := range tc.schema {
+				if _, err := conn.Exec(q); err != nil {
+					t.Fatal(err)
+				}
+			}
+			s, err := NewSQLite(conn)
+			if err != nil {
+				t.Fatalf("NewSQLite() error = %v", err)
+			}
+			got, err := s.TemporaryConnections(context.Background())
+			if err != nil {
+				t.Fatalf("TemporaryConnections() error = %v", err)
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("TemporaryConnections() = %+v, want %+v", got, tc.want)
+			}
+			for i, c := range got {
+				w := tc.want[i]
+				if c.System1ID != w.System1ID || c.System2ID != w.System2ID || (c.Expires == nil) != (w.Expires == nil) ||
+					c.Expires != nil && !c.Expires.Equal(*w.Expires) {
+					t.Errorf("TemporaryConnections()[%d] = %+v, want %+v", i, c, w)
+				}
+			}
+		})
+	}
+}
+
+// TestMongoTemporaryConnection проверяет чтение документов временных соединений
+// MongoDB с полем expires и без него.
+func TestMongoTemporaryConnection(t *testing.T) {
+	expires := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		doc  bson.D
+		want *time.Time
+	}{
+		{doc: bson.D{{Key: "system1id", Value: 1}, {Key: "system2id", Value: 2}}},
+		{doc: bson.D{{Key: "system1id", Value: 1}, {Key: "system2id", Value: 2}, {Key: "expires", Value: expires}}, want: &expires},
+	} {
+		raw, err := bson.Marshal(tc.doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var c TemporaryConnection
+		if err := bson.Unmarshal(raw, &c); err != nil {
+			t.Fatalf("decode %v: %v", tc.doc, err)
+		}
+		if c.System1ID != 1 || c.System2ID != 2 || (c.Expires == nil) != (tc.want == nil) || c.Expires != nil && !c.Expires.Equal(*tc.want) {
+			t.Errorf("decode %v = %+v", tc.doc, c)
+		}
+	}
+}
+
+// TestSQLiteSystems проверяет, что планировщик не прыгает в высокую безопасность
+// по данным SQLite, а таблица без колонок безопасности и региона тоже читается.
+func TestSQLiteSystems(t *testing.T) {
+	open := func(schema ...string) *SQLite {
+		t.Helper()
+		conn, err := sql.Open("sqlite", ":memory:")
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { conn.Close() })
+		conn.SetMaxOpenConns(1)
+		for _, q := range schema {
+			if _, err := conn.Exec(q); err != nil {
+				t.Fatal(err)
+			}
+		}
+		s, err := NewSQLite(conn)
+		if err != nil {
+			t.Fatalf("NewSQLite() error = %v", err)
+		}
+		return s
+	}
+
+	s := open(
+		"CREATE TABLE systems (id INTEGER, name TEXT, x REAL, y REAL, z REAL, security REAL, region_id INTEGER, region TEXT)",
+		"INSERT INTO systems VALUES (1, 'Low', 0, 0, 0, 0.3, 10, 'R'), (2, 'High', 1, 0, 0, 0.9, 10, 'R'), (3, 'Null', 2, 0, 0, -0.2, 10, 'R')",
+	)
+	systems, err := s.Systems(context.Background())
+	if err != nil {
+		t.Fatalf("Systems() error = %v", err)
+	}
+	if got := systems[2]; got.Security != 0.9 || got.RegionID != 10 || got.Region != "R" {
+		t.Fatalf("Systems()[2] = %+v", got)
+	}
+	planner, err := capital.NewPlanner(s, 5)
+	if err != nil {
+		t.Fatalf("NewPlanner() error = %v", err)
+	}
+	if _, err := planner.Plan("Low", "High"); err == nil {
+		t.Error("expected jump into high security space to be rejected")
+	}
+	if _, err := planner.Plan("Low", "Null"); err != nil {
+		t.Errorf("Plan() error = %v", err)
+	}
+
+	s = open(
+		"CREATE TABLE systems (id INTEGER, name TEXT, x REAL, y REAL, z REAL)",
+		"INSERT INTO systems VALUES (1, 'Low', 0, 0, 0)",
+	)
+	systems, err = s.Systems(context.Background())
+	if err != nil {
+		t.Fatalf("Systems() without security columns error = %v", err)
+	}
+	if got := systems[1]; got.Name != "Low" || got.Security != 0 || got.Region != "" {
+		t.Errorf("Systems()[1] = %+v", got)
+	}
+}
@@ -0,0 +1,45 @@
+package db
+
+import (
+	"database/sql"
+
+	"go.mongodb.org/mongo-driver/mongo"
+
+	dbstore "github.com/tkhamez/eve-route-go/internal/dbstore"
+)
+
+// Store описывает операции с базой данных, необходимые приложению.
+type Store = dbstore.Store
+
+// Ansiblex описывает Ansiblex-ворота.
+type Ansiblex = dbstore.Ansiblex
+
+// TemporaryConnection описывает временное соединение между системами.
+type TemporaryConnection = dbstore.TemporaryConnection
+
+// System описывает солнечную систему для капитального маршрута.
+type System = dbstore.System
+
+// ConnectionStore описывает источник Ansiblex и временных соединений.
+type ConnectionStore = dbstore.ConnectionStore
+
+// UserStore описывает хранение настроек маршрута персонажей.
+type UserStore = dbstore.UserStore
+
+// RemovedConnection описывает соединение, исключённое персонажем из маршрутов.
+type RemovedConnection = dbstore.RemovedConnection
+
+// NewMemory создаёт хранилище в памяти.
+func NewMemory(ans []Ansiblex, temps []TemporaryConnection, systems map[int]System) *dbstore.Memory {
+	return dbstore.NewMemory(ans, temps, systems)
+}
+
+// NewPostgres создаёт хранилище PostgreSQL.
+func NewPostgres(conn *sql.DB) (*dbstore.Postgres, error) {
+	return dbstore.NewPostgres(conn)
+}
+
+// NewMongo создаёт хранилище MongoDB.
+func NewMongo(client *mongo.Client, dbName string) *dbstore.Mongo {
+	return dbstore.NewMongo(client, dbName)
+}
@@ -31,3 +31,27 @@ func TestStore(t *testing.T) {
 		t.Fatalf("stored graph mismatch: got %+v, want %+v", got, g)
 	}
 }
+
+// TestLoadGraph проверяет чтение графа с метаданными систем.
+func TestLoadGraph(t *testing.T) {
+	g := graph.DefaultGraph()
+	g.Systems[0].ConstellationID = 20000001
+	g.Systems[0].X, g.Systems[0].MapX = 1e16, 1e16
+	g.Systems[0].FactionID = 500001
+	g.Systems[0].Stations = 2
+	g.Constellations = map[int]string{20000001: "Demo Constellation"}
+	path := filepath.Join(t.TempDir(), "graph.json")
+	if err := StoreGraph(context.Background(), path, g); err != nil {
+		t.Fatalf("StoreGraph() error = %v", err)
+	}
+	got, err := LoadGraph(context.Background(), path)
+	if err != nil {
+		t.Fatalf("LoadGraph() error = %v", err)
+	}
+	if !reflect.DeepEqual(g, got) {
+		t.Fatalf("loaded graph mismatch: got %+v, want %+v", got, g)
+	}
+	if _, err := LoadGraph(context.Background(), filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Fatal("expected error for missing file")
+	}
+}
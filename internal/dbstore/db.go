@@ -1,6 +1,9 @@
 package dbstore
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // Ansiblex represents Ansiblex gate data.
 type Ansiblex struct {
@@ -14,20 +17,49 @@ type Ansiblex struct {
 type TemporaryConnection struct {
 	System1ID int
 	System2ID int
+	// Expires is the time the connection closes; nil if unknown.
+	Expires *time.Time `bson:"expires,omitempty"`
 }
 
 // System represents a solar system for capital routes.
 type System struct {
-	ID   int
-	Name string
-	X    float64
-	Y    float64
-	Z    float64
+	ID       int
+	Name     string
+	X        float64
+	Y        float64
+	Z        float64
+	Security float64
+	RegionID int
+	Region   string
+}
+
+// RemovedConnection is a connection between two systems that a character
+// excluded from their route searches.
+type RemovedConnection struct {
+	System1ID int
+	System2ID int
 }
 
 // Store describes database operations required by the application.
 type Store interface {
+	ConnectionStore
+	Systems(ctx context.Context) (map[int]System, error)
+	UserStore
+}
+
+// UserStore persists the route settings of each character: avoided systems
+// and removed connections. Adding an existing entry is not an error.
+type UserStore interface {
+	AvoidedSystems(ctx context.Context, characterID int) ([]int, error)
+	AddAvoidedSystem(ctx context.Context, characterID, systemID int) error
+	RemovedConnections(ctx context.Context, characterID int) ([]RemovedConnection, error)
+	AddRemovedConnection(ctx context.Context, characterID int, c RemovedConnection) error
+	ResetRoute(ctx context.Context, characterID int) error
+}
+
+// ConnectionStore provides the Ansiblex gates and temporary connections
+// that extend the stargate network.
+type ConnectionStore interface {
 	Ansiblexes(ctx context.Context) ([]Ansiblex, error)
 	TemporaryConnections(ctx context.Context) ([]TemporaryConnection, error)
-	Systems(ctx context.Context) (map[int]System, error)
 }
@@ -1,12 +1,20 @@
 package dbstore
 
-import "context"
+import (
+	"context"
+	"sort"
+	"sync"
+)
 
 // Memory provides an in-memory implementation of Store for tests.
 type Memory struct {
 	ansiblexes      []Ansiblex
 	tempConnections []TemporaryConnection
 	systems         map[int]System
+
+	mu      sync.Mutex
+	avoided map[int]map[int]bool
+	removed map[int]map[RemovedConnection]bool
 }
 
 // NewMemory creates a new in-memory store instance.
@@ -20,7 +28,13 @@ func NewMemory(ans []Ansiblex, temps []TemporaryConnection, systems map[int]Syst
 	if systems == nil {
 		systems = map[int]System{}
 	}
-	return &Memory{ansiblexes: ans, tempConnections: temps, systems: systems}
+	return &Memory{
+		ansiblexes:      ans,
+		tempConnections: temps,
+		systems:         systems,
+		avoided:         map[int]map[int]bool{},
+		removed:         map[int]map[RemovedConnection]bool{},
+	}
 }
 
 // Ansiblexes returns all Ansiblex gates.
@@ -37,3 +51,64 @@ func (m *Memory) TemporaryConnections(ctx context.Context) ([]TemporaryConnectio
 func (m *Memory) Systems(ctx context.Context) (map[int]System, error) {
 	return m.systems, nil
 }
+
+// AvoidedSystems returns the systems avoided by the character, ordered by ID.
+func (m *Memory) AvoidedSystems(ctx context.Context, characterID int) ([]int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	res := []int{}
+	for id := range m.avoided[characterID] {
+		res = append(res, id)
+	}
+	sort.Ints(res)
+	return res, nil
+}
+
+// AddAvoidedSystem adds a system to the avoided systems of the character.
+func (m *Memory) AddAvoidedSystem(ctx context.Context, characterID, systemID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.avoided[characterID] == nil {
+		m.avoided[characterID] = map[int]bool{}
+	}
+	m.avoided[characterID][systemID] = true
+	return nil
+}
+
+// RemovedConnections returns the connections removed by the character,
+// ordered by system IDs.
+func (m *Memory) RemovedConnections(ctx context.Context, characterID int) ([]RemovedConnection, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	res := []RemovedConnection{}
+	for c := range m.removed[characterID] {
+		res = append(res, c)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].System1ID != res[j].System1ID {
+			return res[i].System1ID < res[j].System1ID
+		}
+		return res[i].System2ID < res[j].System2ID
+	})
+	return res, nil
+}
+
+// AddRemovedConnection adds a connection to the removed connections of the character.
+func (m *Memory) AddRemovedConnection(ctx context.Context, characterID int, c RemovedConnection) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.removed[characterID] == nil {
+		m.removed[characterID] = map[RemovedConnection]bool{}
+	}
+	m.removed[characterID][c] = true
+	return nil
+}
+
+// ResetRoute deletes all avoided systems and removed connections of the character.
+func (m *Memory) ResetRoute(ctx context.Context, characterID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.avoided, characterID)
+	delete(m.removed, characterID)
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // Mongo implements Store using MongoDB.
@@ -80,6 +81,81 @@ func (m *Mongo) Systems(ctx context.Context) (map[int]System, error) {
 	return systems, nil
 }
 
+// avoidedSystem is a document of the avoided_systems collection.
+type avoidedSystem struct {
+	CharacterID int `bson:"character_id"`
+	SystemID    int `bson:"system_id"`
+}
+
+// removedConnection is a document of the removed_connections collection.
+type removedConnection struct {
+	CharacterID int `bson:"character_id"`
+	System1ID   int `bson:"system1_id"`
+	System2ID   int `bson:"system2_id"`
+}
+
+// AvoidedSystems loads the systems avoided by the character from MongoDB.
+func (m *Mongo) AvoidedSystems(ctx context.Context, characterID int) ([]int, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "system_id", Value: 1}})
+	cur, err := m.collection("avoided_systems").Find(ctx, bson.D{{Key: "character_id", Value: characterID}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	res := []int{}
+	for cur.Next(ctx) {
+		var a avoidedSystem
+		if err := cur.Decode(&a); err != nil {
+			return nil, err
+		}
+		res = append(res, a.SystemID)
+	}
+	return res, cur.Err()
+}
+
+// AddAvoidedSystem stores an avoided system of the character in MongoDB.
+func (m *Mongo) AddAvoidedSystem(ctx context.Context, characterID, systemID int) error {
+	doc := avoidedSystem{CharacterID: characterID, SystemID: systemID}
+	_, err := m.collection("avoided_systems").ReplaceOne(ctx, doc, doc, options.Replace().SetUpsert(true))
+	return err
+}
+
+// RemovedConnections loads the connections removed by the character from MongoDB.
+func (m *Mongo) RemovedConnections(ctx context.Context, characterID int) ([]RemovedConnection, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "system1_id", Value: 1}, {Key: "system2_id", Value: 1}})
+	cur, err := m.collection("removed_connections").Find(ctx, bson.D{{Key: "character_id", Value: characterID}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	res := []RemovedConnection{}
+	for cur.Next(ctx) {
+		var c removedConnection
+		if err := cur.Decode(&c); err != nil {
+			return nil, err
+		}
+		res = append(res, RemovedConnection{System1ID: c.System1ID, System2ID: c.System2ID})
+	}
+	return res, cur.Err()
+}
+
+// AddRemovedConnection stores a removed connection of the character in MongoDB.
+func (m *Mongo) AddRemovedConnection(ctx context.Context, characterID int, c RemovedConnection) error {
+	doc := removedConnection{CharacterID: characterID, System1ID: c.System1ID, System2ID: c.System2ID}
+	_, err := m.collection("removed_connections").ReplaceOne(ctx, doc, doc, options.Replace().SetUpsert(true))
+	return err
+}
+
+// ResetRoute deletes all avoided systems and removed connections of the character from MongoDB.
+func (m *Mongo) ResetRoute(ctx context.Context, characterID int) error {
+	filter := bson.D{{Key: "character_id", Value: characterID}}
+	if _, err := m.collection("avoided_systems").DeleteMany(ctx, filter); err != nil {
+		return err
+	}
+	_, err := m.collection("removed_connections").DeleteMany(ctx, filter)
+	return err
+}
+
 // EnsureMongoConnection pings the database to check connection.
 func (m *Mongo) EnsureMongoConnection(ctx context.Context) {
 	if err := m.client.Ping(ctx, nil); err != nil {
@@ -13,9 +13,36 @@ type Postgres struct {
 	db *sql.DB
 }
 
-// NewPostgres creates a new Postgres store.
-func NewPostgres(db *sql.DB) *Postgres {
-	return &Postgres{db: db}
+// NewPostgres creates a new Postgres store and ensures the schema of the
+// route settings tables. It fails if the tables cannot be created.
+func NewPostgres(db *sql.DB) (*Postgres, error) {
+	p := &Postgres{db: db}
+	if err := p.init(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *Postgres) init() error {
+	for _, q := range []string{
+		`CREATE TABLE IF NOT EXISTS avoided_systems (
+        character_id INTEGER NOT NULL,
+        system_id INTEGER NOT NULL,
+        PRIMARY KEY (character_id, system_id)
+    )`,
+		`CREATE TABLE IF NOT EXISTS removed_connections (
+        character_id INTEGER NOT NULL,
+        system1_id INTEGER NOT NULL,
+        system2_id INTEGER NOT NULL,
+        PRIMARY KEY (character_id, system1_id, system2_id)
+    )`,
+	} {
+		if _, err := p.db.Exec(q); err != nil {
+			log.Println("create table:", err)
+			return err
+		}
+	}
+	return nil
 }
 
 // Ansiblexes loads Ansiblex gates from PostgreSQL.
@@ -37,8 +64,19 @@ func (p *Postgres) Ansiblexes(ctx context.Context) ([]Ansiblex, error) {
 }
 
 // TemporaryConnections loads temporary connections from PostgreSQL.
+// Databases without the expires column are supported, their connections
+// have no expiry time.
 func (p *Postgres) TemporaryConnections(ctx context.Context) ([]TemporaryConnection, error) {
-	rows, err := p.db.QueryContext(ctx, "SELECT system1_id, system2_id FROM temporary_connections")
+	var hasExpires bool
+	err := p.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = 'temporary_connections' AND column_name = 'expires')").Scan(&hasExpires)
+	if err != nil {
+		return nil, err
+	}
+	query := "SELECT system1_id, system2_id, NULL FROM temporary_connections"
+	if hasExpires {
+		query = "SELECT system1_id, system2_id, expires FROM temporary_connections"
+	}
+	rows, err := p.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -46,7 +84,7 @@ func (p *Postgres) TemporaryConnections(ctx context.Context) ([]TemporaryConnect
 	var res []TemporaryConnection
 	for rows.Next() {
 		var c TemporaryConnection
-		if err := rows.Scan(&c.System1ID, &c.System2ID); err != nil {
+		if err := rows.Scan(&c.System1ID, &c.System2ID, &c.Expires); err != nil {
 			return nil, err
 		}
 		res = append(res, c)
@@ -54,9 +92,24 @@ func (p *Postgres) TemporaryConnections(ctx context.Context) ([]TemporaryConnect
 	return res, rows.Err()
 }
 
-// Systems loads capital systems from PostgreSQL.
+// Systems loads capital systems from PostgreSQL. The security, region_id and
+// region columns are optional; without them the values are zero.
 func (p *Postgres) Systems(ctx context.Context) (map[int]System, error) {
-	rows, err := p.db.QueryContext(ctx, "SELECT id, name, x, y, z FROM systems")
+	query := "SELECT id, name, x, y, z"
+	for _, c := range []struct{ name, zero string }{{"security", "0"}, {"region_id", "0"}, {"region", "''"}} {
+		var ok bool
+		err := p.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = 'systems' AND column_name = $1)", c.name).Scan(&ok)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			query += ", COALESCE(" + c.name + ", " + c.zero + ")"
+		} else {
+			log.Printf("systems table has no %s column", c.name)
+			query += ", " + c.zero
+		}
+	}
+	rows, err := p.db.QueryContext(ctx, query+" FROM systems")
 	if err != nil {
 		return nil, err
 	}
@@ -64,7 +117,7 @@ func (p *Postgres) Systems(ctx context.Context) (map[int]System, error) {
 	systems := make(map[int]System)
 	for rows.Next() {
 		var s System
-		if err := rows.Scan(&s.ID, &s.Name, &s.X, &s.Y, &s.Z); err != nil {
+		if err := rows.Scan(&s.ID, &s.Name, &s.X, &s.Y, &s.Z, &s.Security, &s.RegionID, &s.Region); err != nil {
 			return nil, err
 		}
 		systems[s.ID] = s
@@ -72,6 +125,70 @@ func (p *Postgres) Systems(ctx context.Context) (map[int]System, error) {
 	return systems, rows.Err()
 }
 
+// AvoidedSystems loads the systems avoided by the character from PostgreSQL.
+func (p *Postgres) AvoidedSystems(ctx context.Context, characterID int) ([]int, error) {
+	rows, err := p.db.QueryContext(ctx, "SELECT system_id FROM avoided_systems WHERE character_id = $1 ORDER BY system_id", characterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		res = append(res, id)
+	}
+	return res, rows.Err()
+}
+
+// AddAvoidedSystem stores an avoided system of the character in PostgreSQL.
+func (p *Postgres) AddAvoidedSystem(ctx context.Context, characterID, systemID int) error {
+	_, err := p.db.ExecContext(ctx, "INSERT INTO avoided_systems (character_id, system_id) VALUES ($1, $2) ON CONFLICT DO NOTHING", characterID, systemID)
+	return err
+}
+
+// RemovedConnections loads the connections removed by the character from PostgreSQL.
+func (p *Postgres) RemovedConnections(ctx context.Context, characterID int) ([]RemovedConnection, error) {
+	rows, err := p.db.QueryContext(ctx, "SELECT system1_id, system2_id FROM removed_connections WHERE character_id = $1 ORDER BY system1_id, system2_id", characterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := []RemovedConnection{}
+	for rows.Next() {
+		var c RemovedConnection
+		if err := rows.Scan(&c.System1ID, &c.System2ID); err != nil {
+			return nil, err
+		}
+		res = append(res, c)
+	}
+	return res, rows.Err()
+}
+
+// AddRemovedConnection stores a removed connection of the character in PostgreSQL.
+func (p *Postgres) AddRemovedConnection(ctx context.Context, characterID int, c RemovedConnection) error {
+	_, err := p.db.ExecContext(ctx, "INSERT INTO removed_connections (character_id, system1_id, system2_id) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING", characterID, c.System1ID, c.System2ID)
+	return err
+}
+
+// ResetRoute deletes all avoided systems and removed connections of the character from PostgreSQL.
+func (p *Postgres) ResetRoute(ctx context.Context, characterID int) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, "DELETE FROM avoided_systems WHERE character_id = $1", characterID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM removed_connections WHERE character_id = $1", characterID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
 // EnsurePostgresConnection pings the database to check connection.
 func (p *Postgres) EnsurePostgresConnection(ctx context.Context) {
 	if err := p.db.PingContext(ctx); err != nil {
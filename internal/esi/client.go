@@ -32,10 +32,17 @@ func (c *Client) Status(ctx context.Context) (esi.GetStatusOk, error) {
 
 // System содержит данные о солнечной системе и списке её стражевых ворот.
 type System struct {
-	ID        int32
-	Name      string
-	Security  float64
-	RegionID  int32
+	ID                int32
+	Name              string
+	Security          float64
+	RegionID          int32
+	ConstellationID   int32
+	ConstellationName string
+	// X, Y, Z — координаты системы в метрах.
+	X         float64
+	Y         float64
+	Z         float64
+	Stations  int
 	Stargates []int32
 }
 
@@ -57,11 +64,17 @@ func (c *Client) Systems(ctx context.Context) ([]System, error) {
 			return nil, err
 		}
 		systems = append(systems, System{
-			ID:        id,
-			Name:      sys.Name,
-			Security:  float64(sys.SecurityStatus),
-			RegionID:  constel.RegionId,
-			Stargates: sys.Stargates,
+			ID:                id,
+			Name:              sys.Name,
+			Security:          float64(sys.SecurityStatus),
+			RegionID:          constel.RegionId,
+			ConstellationID:   sys.ConstellationId,
+			ConstellationName: constel.Name,
+			X:                 sys.Position.X,
+			Y:                 sys.Position.Y,
+			Z:                 sys.Position.Z,
+			Stations:          len(sys.Stations),
+			Stargates:         sys.Stargates,
 		})
 	}
 	return systems, nil
@@ -100,3 +113,20 @@ func (c *Client) RegionName(ctx context.Context, id int32) (string, error) {
 	}
 	return r.Name, nil
 }
+
+// FactionOwners возвращает NPC-фракции, владеющие системами, по карте суверенитета.
+// Ключ — ID системы; системы без фракции-владельца не включаются.
+func (c *Client) FactionOwners(ctx context.Context) (map[int32]int32, error) {
+	log.Print("esi: requesting sovereignty map")
+	sov, _, err := c.api.ESI.SovereigntyApi.GetSovereigntyMap(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	owners := make(map[int32]int32)
+	for _, s := range sov {
+		if s.FactionId != 0 {
+			owners[s.SystemId] = s.FactionId
+		}
+	}
+	return owners, nil
+}
@@ -0,0 +1,252 @@
+package graph
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+)
+
+// securityEpsilon — изменения уровня безопасности меньше этого значения
+// считаются погрешностью округления и не сообщаются.
+const securityEpsilon = 1e-6
+
+// SystemRef — краткое описание системы в отчёте о различиях.
+type SystemRef struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	Region string `json:"region,omitempty"`
+}
+
+// Rename — система сохранила ID, но сменила название.
+type Rename struct {
+	ID      int    `json:"id"`
+	OldName string `json:"oldName"`
+	NewName string `json:"newName"`
+}
+
+// SecurityChange — изменение уровня безопасности системы.
+type SecurityChange struct {
+	System SystemRef `json:"system"`
+	Old    float64   `json:"old"`
+	New    float64   `json:"new"`
+}
+
+// RegionMove — перенос системы в другой регион.
+type RegionMove struct {
+	System      SystemRef `json:"system"`
+	OldRegionID int       `json:"oldRegionId"`
+	OldRegion   string    `json:"oldRegion"`
+	NewRegionID int       `json:"newRegionId"`
+	NewRegion   string    `json:"newRegion"`
+}
+
+// ConnectionRef — звёздные врата между двумя системами.
+type ConnectionRef struct {
+	From SystemRef `json:"from"`
+	To   SystemRef `json:"to"`
+}
+
+// Diff описывает различия между двумя версиями карты.
+// Все списки отсортированы по ID систем, поэтому результат детерминирован.
+type Diff struct {
+	AddedSystems       []SystemRef      `json:"addedSystems"`
+	RemovedSystems     []SystemRef      `json:"removedSystems"`
+	Renamed            []Rename         `json:"renamed"`
+	SecurityChanged    []SecurityChange `json:"securityChanged"`
+	RegionMoved        []RegionMove     `json:"regionMoved"`
+	AddedConnections   []ConnectionRef  `json:"addedConnections"`
+	RemovedConnections []ConnectionRef  `json:"removedConnections"`
+}
+
+// Compare сравнивает две версии графа. Системы сопоставляются по ID,
+// соединения — по неупорядоченной паре ID.
+func Compare(before, after Graph) Diff {
+	d := Diff{
+		AddedSystems:       []SystemRef{},
+		RemovedSystems:     []SystemRef{},
+		Renamed:            []Rename{},
+		SecurityChanged:    []SecurityChange{},
+		RegionMoved:        []RegionMove{},
+		AddedConnections:   []ConnectionRef{},
+		RemovedConnections: []ConnectionRef{},
+	}
+	oldSystems := systemsByID(before)
+	newSystems := systemsByID(after)
+
+	for _, id := range sortedIDs(newSystems) {
+		ns := newSystems[id]
+		prev, ok := oldSystems[id]
+		if !ok {
+			d.AddedSystems = append(d.AddedSystems, ref(after, ns))
+			continue
+		}
+		if prev.Name != ns.Name {
+			d.Renamed = append(d.Renamed, Rename{ID: id, OldName: prev.Name, NewName: ns.Name})
+		}
+		if math.Abs(prev.Security-ns.Security) > securityEpsilon {
+			d.SecurityChanged = append(d.SecurityChanged, SecurityChange{System: ref(after, ns), Old: prev.Security, New: ns.Security})
+		}
+		if prev.RegionID != ns.RegionID {
+			d.RegionMoved = append(d.RegionMoved, RegionMove{
+				System:      ref(after, ns),
+				OldRegionID: prev.RegionID,
+				OldRegion:   before.Regions[prev.RegionID],
+				NewRegionID: ns.RegionID,
+				NewRegion:   after.Regions[ns.RegionID],
+			})
+		}
+	}
+	for _, id := range sortedIDs(oldSystems) {
+		if _, ok := newSystems[id]; !ok {
+			d.RemovedSystems = append(d.RemovedSystems, ref(before, oldSystems[id]))
+		}
+	}
+
+	oldConns := connectionSet(before)
+	newConns := connectionSet(after)
+	for _, c := range sortedPairs(newConns) {
+		if !oldConns[c] {
+			d.AddedConnections = append(d.AddedConnections, connRef(after, newSystems, c))
+		}
+	}
+	for _, c := range sortedPairs(oldConns) {
+		if !newConns[c] {
+			d.RemovedConnections = append(d.RemovedConnections, connRef(before, oldSystems, c))
+		}
+	}
+	return d
+}
+
+// Empty сообщает, что версии карты не различаются.
+func (d Diff) Empty() bool {
+	return len(d.AddedSystems) == 0 && len(d.RemovedSystems) == 0 && len(d.Renamed) == 0 &&
+		len(d.SecurityChanged) == 0 && len(d.RegionMoved) == 0 &&
+		len(d.AddedConnections) == 0 && len(d.RemovedConnections) == 0
+}
+
+// WriteText выводит различия в удобном для чтения виде.
+func (d Diff) WriteText(w io.Writer) error {
+	if d.Empty() {
+		_, err := fmt.Fprintln(w, "no changes")
+		return err
+	}
+	var lines []string
+	add := func(format string, args ...any) { lines = append(lines, fmt.Sprintf(format, args...)) }
+	if len(d.AddedSystems) > 0 {
+		add("Added systems (%d):", len(d.AddedSystems))
+		for _, s := range d.AddedSystems {
+			add("  + %s", s)
+		}
+	}
+	if len(d.RemovedSystems) > 0 {
+		add("Removed systems (%d):", len(d.RemovedSystems))
+		for _, s := range d.RemovedSystems {
+			add("  - %s", s)
+		}
+	}
+	if len(d.Renamed) > 0 {
+		add("Renamed systems (%d):", len(d.Renamed))
+		for _, r := range d.Renamed {
+			add("  ~ %d: %s -> %s", r.ID, r.OldName, r.NewName)
+		}
+	}
+	if len(d.SecurityChanged) > 0 {
+		add("Security changes (%d):", len(d.SecurityChanged))
+		for _, c := range d.SecurityChanged {
+			add("  ~ %s: %.3f -> %.3f", c.System, c.Old, c.New)
+		}
+	}
+	if len(d.RegionMoved) > 0 {
+		add("Region moves (%d):", len(d.RegionMoved))
+		for _, m := range d.RegionMoved {
+			add("  ~ %s (%d): %s -> %s", m.System.Name, m.System.ID, m.OldRegion, m.NewRegion)
+		}
+	}
+	if len(d.AddedConnections) > 0 {
+		add("Added connections (%d):", len(d.AddedConnections))
+		for _, c := range d.AddedConnections {
+			add("  + %s <-> %s", c.From, c.To)
+		}
+	}
+	if len(d.RemovedConnections) > 0 {
+		add("Removed connections (%d):", len(d.RemovedConnections))
+		for _, c := range d.RemovedConnections {
+			add("  - %s <-> %s", c.From, c.To)
+		}
+	}
+	for _, l := range lines {
+		if _, err := fmt.Fprintln(w, l); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// String возвращает название, ID и регион системы.
+func (s SystemRef) String() string {
+	if s.Region == "" {
+		return fmt.Sprintf("%s (%d)", s.Name, s.ID)
+	}
+	return fmt.Sprintf("%s (%d, %s)", s.Name, s.ID, s.Region)
+}
+
+func systemsByID(g Graph) map[int]System {
+	m := make(map[int]System, len(g.Systems))
+	for _, s := range g.Systems {
+		m[s.ID] = s
+	}
+	return m
+}
+
+func sortedIDs(m map[int]System) []int {
+	ids := make([]int, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// connectionSet возвращает соединения графа как упорядоченные пары ID.
+func connectionSet(g Graph) map[[2]int]bool {
+	m := make(map[[2]int]bool, len(g.Connections))
+	for _, c := range g.Connections {
+		if c[0] > c[1] {
+			c[0], c[1] = c[1], c[0]
+		}
+		m[c] = true
+	}
+	return m
+}
+
+func sortedPairs(m map[[2]int]bool) [][2]int {
+	pairs := make([][2]int, 0, len(m))
+	for p := range m {
+		pairs = append(pairs, p)
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i][0] != pairs[j][0] {
+			return pairs[i][0] < pairs[j][0]
+		}
+		return pairs[i][1] < pairs[j][1]
+	})
+	return pairs
+}
+
+func ref(g Graph, s System) SystemRef {
+	return SystemRef{ID: s.ID, Name: s.Name, Region: g.Regions[s.RegionID]}
+}
+
+// connRef описывает соединение; системы, отсутствующие в графе, остаются без названия.
+func connRef(g Graph, systems map[int]System, c [2]int) ConnectionRef {
+	from := SystemRef{ID: c[0]}
+	to := SystemRef{ID: c[1]}
+	if s, ok := systems[c[0]]; ok {
+		from = ref(g, s)
+	}
+	if s, ok := systems[c[1]]; ok {
+		to = ref(g, s)
+	}
+	return ConnectionRef{From: from, To: to}
+}
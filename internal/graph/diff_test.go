@@ -0,0 +1,68 @@
+package graph
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestCompare проверяет все виды различий между версиями карты.
+func TestCompare(t *testing.T) {
+	old := DefaultGraph()
+	next := DefaultGraph()
+	next.Systems = []System{
+		{ID: 1, Name: "Alpha", Security: 0.5, RegionID: 1},
+		{ID: 2, Name: "Bravo", Security: 0.6, RegionID: 1},
+		{ID: 3, Name: "Gamma", Security: 0.4, RegionID: 2},
+		{ID: 4, Name: "Delta", Security: -0.2, RegionID: 2},
+	}
+	next.Regions = map[int]string{1: "Demo Region", 2: "Pochven"}
+	// соединение 1-3 удалено, 3-4 добавлено; порядок пары не важен
+	next.Connections = [][2]int{{2, 1}, {2, 3}, {4, 3}}
+
+	d := Compare(old, next)
+	if len(d.AddedSystems) != 1 || d.AddedSystems[0].Name != "Delta" || d.AddedSystems[0].Region != "Pochven" {
+		t.Errorf("неверные добавленные системы: %+v", d.AddedSystems)
+	}
+	if len(d.RemovedSystems) != 0 {
+		t.Errorf("неожиданные удалённые системы: %+v", d.RemovedSystems)
+	}
+	if len(d.Renamed) != 1 || d.Renamed[0].OldName != "Beta" || d.Renamed[0].NewName != "Bravo" {
+		t.Errorf("неверные переименования: %+v", d.Renamed)
+	}
+	if len(d.SecurityChanged) != 1 || d.SecurityChanged[0].System.ID != 3 || d.SecurityChanged[0].New != 0.4 {
+		t.Errorf("неверные изменения безопасности: %+v", d.SecurityChanged)
+	}
+	if len(d.RegionMoved) != 1 || d.RegionMoved[0].OldRegion != "Demo Region" || d.RegionMoved[0].NewRegion != "Pochven" {
+		t.Errorf("неверные переносы: %+v", d.RegionMoved)
+	}
+	if len(d.AddedConnections) != 1 || d.AddedConnections[0].From.ID != 3 || d.AddedConnections[0].To.ID != 4 {
+		t.Errorf("неверные добавленные соединения: %+v", d.AddedConnections)
+	}
+	if len(d.RemovedConnections) != 1 || d.RemovedConnections[0].From.ID != 1 || d.RemovedConnections[0].To.ID != 3 {
+		t.Errorf("неверные удалённые соединения: %+v", d.RemovedConnections)
+	}
+
+	var buf bytes.Buffer
+	if err := d.WriteText(&buf); err != nil {
+		t.Fatalf("WriteText: %v", err)
+	}
+	for _, want := range []string{"+ Delta (4, Pochven)", "~ 2: Beta -> Bravo", "0.700 -> 0.400", "- Alpha (1, Demo Region) <-> Gamma (3, Demo Region)"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("в отчёте нет %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+// TestCompareEqual проверяет, что одинаковые графы не различаются.
+func TestCompareEqual(t *testing.T) {
+	d := Compare(DefaultGraph(), DefaultGraph())
+	if !d.Empty() {
+		t.Fatalf("ожидалось отсутствие различий: %+v", d)
+	}
+	var buf bytes.Buffer
+	_ = d.WriteText(&buf)
+	if buf.String() != "no changes\n" {
+		t.Fatalf("неожиданный отчёт: %q", buf.String())
+	}
+}
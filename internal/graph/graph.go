@@ -2,17 +2,41 @@ package graph
 
 // System представляет солнечную систему EVE.
 type System struct {
-	ID       int
-	Name     string
-	Security float64
-	RegionID int
+	ID              int
+	Name            string
+	Security        float64
+	RegionID        int
+	ConstellationID int
+	// X, Y, Z — координаты системы во вселенной в метрах.
+	X float64
+	Y float64
+	Z float64
+	// MapX, MapY — положение системы на двумерной карте.
+	MapX float64
+	MapY float64
+	// FactionID — NPC-фракция, владеющая системой (0, если владельца нет).
+	FactionID int
+	// Stations — количество NPC-станций в системе.
+	Stations int
+}
+
+// HasPosition сообщает, известны ли координаты системы.
+func (s System) HasPosition() bool {
+	return s.X != 0 || s.Y != 0 || s.Z != 0
+}
+
+// ProjectMap возвращает положение системы на двумерной карте при взгляде
+// на вселенную сверху; используется, если готовой проекции нет.
+func ProjectMap(x, z float64) (float64, float64) {
+	return x, -z
 }
 
 // Graph хранит минимальные данные о карте.
 type Graph struct {
-	Systems     []System
-	Connections [][2]int // каждая пара содержит ID соединённых систем
-	Regions     map[int]string
+	Systems        []System
+	Connections    [][2]int // каждая пара содержит ID соединённых систем
+	Regions        map[int]string
+	Constellations map[int]string
 }
 
 // DefaultGraph возвращает небольшой пример графа.
@@ -37,6 +37,11 @@ func (h *Helper) FindSystem(id int) *System {
 	return nil
 }
 
+// AnsiblexName формирует название Ansiblex в формате, который понимает GetEndSystem.
+func AnsiblexName(start, end, label string) string {
+	return start + " » " + end + " - " + label
+}
+
 // GetEndSystem возвращает конечную систему из названия Ansiblex.
 // Формат названия: "Start » End - ...".
 func (h *Helper) GetEndSystem(ansiblexName string) *System {
@@ -60,3 +60,14 @@ func TestGetEndSystem(t *testing.T) {
 		t.Errorf("ожидалось nil при отсутствии системы, получено %+v", res)
 	}
 }
+
+// TestAnsiblexName проверяет, что сформированное название разбирается обратно.
+func TestAnsiblexName(t *testing.T) {
+	name := AnsiblexName("Alpha", "Gamma", "Proposed")
+	if name != "Alpha » Gamma - Proposed" {
+		t.Fatalf("неверное название: %q", name)
+	}
+	if end := NewHelper(DefaultGraph()).GetEndSystem(name); end == nil || end.ID != 3 {
+		t.Fatalf("ожидалась Gamma, получено %#v", end)
+	}
+}
@@ -0,0 +1,209 @@
+package graph
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+)
+
+// Severity — важность найденной проблемы.
+type Severity string
+
+const (
+	// SeverityError — граф нельзя использовать: данные будут потеряны или искажены.
+	SeverityError Severity = "error"
+	// SeverityWarning — граф пригоден, но выглядит подозрительно.
+	SeverityWarning Severity = "warning"
+)
+
+// Коды проблем, которые находит Validate.
+const (
+	CodeDuplicateID          = "duplicate-id"
+	CodeDuplicateName        = "duplicate-name"
+	CodeUnknownRegion        = "unknown-region"
+	CodeUnknownConstellation = "unknown-constellation"
+	CodeDanglingConnection   = "dangling-connection"
+	CodeSelfLoop             = "self-loop"
+	CodeDuplicateConnection  = "duplicate-connection"
+	CodeDisconnected         = "disconnected"
+)
+
+// Finding — одна проблема графа.
+type Finding struct {
+	Severity  Severity `json:"severity"`
+	Code      string   `json:"code"`
+	Message   string   `json:"message"`
+	SystemIDs []int    `json:"systemIds,omitempty"`
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s %s: %s", f.Severity, f.Code, f.Message)
+}
+
+// Report — результат проверки графа.
+type Report struct {
+	Findings []Finding `json:"findings"`
+}
+
+// HasErrors сообщает, есть ли среди проблем ошибки.
+func (r Report) HasErrors() bool {
+	for _, f := range r.Findings {
+		if f.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Count возвращает количество проблем указанной важности.
+func (r Report) Count(s Severity) int {
+	n := 0
+	for _, f := range r.Findings {
+		if f.Severity == s {
+			n++
+		}
+	}
+	return n
+}
+
+// Validate проверяет целостность графа: уникальность ID и названий систем,
+// известность регионов и созвездий, корректность соединений и связность карты.
+// Созвездия проверяются, только если граф содержит их список.
+// Порядок проблем детерминирован.
+func Validate(g Graph) Report {
+	r := Report{Findings: []Finding{}}
+	add := func(s Severity, code string, ids []int, format string, args ...any) {
+		r.Findings = append(r.Findings, Finding{Severity: s, Code: code, Message: fmt.Sprintf(format, args...), SystemIDs: ids})
+	}
+
+	systems := make(map[int]System, len(g.Systems))
+	names := map[string][]int{}
+	for _, s := range g.Systems {
+		if _, ok := systems[s.ID]; ok {
+			add(SeverityError, CodeDuplicateID, []int{s.ID}, "system ID %d is used more than once", s.ID)
+			continue
+		}
+		systems[s.ID] = s
+		key := strings.ToLower(s.Name)
+		names[key] = append(names[key], s.ID)
+		if _, ok := g.Regions[s.RegionID]; !ok {
+			add(SeverityError, CodeUnknownRegion, []int{s.ID}, "system %s (%d) has unknown region %d", s.Name, s.ID, s.RegionID)
+		}
+		if g.Constellations != nil {
+			if _, ok := g.Constellations[s.ConstellationID]; !ok {
+				add(SeverityWarning, CodeUnknownConstellation, []int{s.ID},
+					"system %s (%d) has unknown constellation %d", s.Name, s.ID, s.ConstellationID)
+			}
+		}
+	}
+	var dupNames []string
+	for name, ids := range names {
+		if len(ids) > 1 {
+			dupNames = append(dupNames, name)
+		}
+	}
+	sort.Strings(dupNames)
+	for _, name := range dupNames {
+		ids := names[name]
+		// поиск по названию без учёта регистра найдёт только первую систему
+		add(SeverityError, CodeDuplicateName, ids, "system name %q is used by %d systems", systems[ids[0]].Name, len(ids))
+	}
+
+	adj := make(map[int][]int, len(systems))
+	seen := map[[2]int]bool{}
+	for _, c := range g.Connections {
+		_, ok1 := systems[c[0]]
+		_, ok2 := systems[c[1]]
+		if !ok1 || !ok2 {
+			add(SeverityError, CodeDanglingConnection, []int{c[0], c[1]}, "connection %d-%d references an unknown system", c[0], c[1])
+			continue
+		}
+		if c[0] == c[1] {
+			add(SeverityWarning, CodeSelfLoop, []int{c[0]}, "system %s (%d) is connected to itself", systems[c[0]].Name, c[0])
+			continue
+		}
+		pair := c
+		if pair[0] > pair[1] {
+			pair[0], pair[1] = pair[1], pair[0]
+		}
+		if seen[pair] {
+			add(SeverityWarning, CodeDuplicateConnection, []int{pair[0], pair[1]},
+				"connection %s-%s is listed more than once", systems[pair[0]].Name, systems[pair[1]].Name)
+			continue
+		}
+		seen[pair] = true
+		adj[pair[0]] = append(adj[pair[0]], pair[1])
+		adj[pair[1]] = append(adj[pair[1]], pair[0])
+	}
+
+	if unreachable, components := disconnected(systems, adj); len(unreachable) > 0 {
+		add(SeverityWarning, CodeDisconnected, unreachable,
+			"%d systems in %d components are not reachable from the largest component", len(unreachable), components)
+	}
+	return r
+}
+
+// disconnected возвращает отсортированные ID систем вне самой большой компоненты
+// связности и количество таких компонент.
+func disconnected(systems map[int]System, adj map[int][]int) ([]int, int) {
+	ids := make([]int, 0, len(systems))
+	for id := range systems {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	component := make(map[int]int, len(ids))
+	var sizes []int
+	for _, id := range ids {
+		if _, ok := component[id]; ok {
+			continue
+		}
+		c := len(sizes)
+		component[id] = c
+		size := 0
+		queue := []int{id}
+		for len(queue) > 0 {
+			cur := queue[0]
+			queue = queue[1:]
+			size++
+			for _, next := range adj[cur] {
+				if _, ok := component[next]; !ok {
+					component[next] = c
+					queue = append(queue, next)
+				}
+			}
+		}
+		sizes = append(sizes, size)
+	}
+	if len(sizes) <= 1 {
+		return nil, 0
+	}
+	largest := 0
+	for c, size := range sizes {
+		if size > sizes[largest] {
+			largest = c
+		}
+	}
+	var unreachable []int
+	for _, id := range ids {
+		if component[id] != largest {
+			unreachable = append(unreachable, id)
+		}
+	}
+	return unreachable, len(sizes) - 1
+}
+
+// Check проверяет граф и пишет найденные проблемы в журнал с префиксом prefix.
+// В строгом режиме граф с ошибками отклоняется.
+func Check(g Graph, strict bool, prefix string) (Report, error) {
+	r := Validate(g)
+	for _, f := range r.Findings {
+		log.Printf("%s: graph %s", prefix, f)
+	}
+	errs := r.Count(SeverityError)
+	log.Printf("%s: graph validation found %d errors and %d warnings", prefix, errs, r.Count(SeverityWarning))
+	if strict && errs > 0 {
+		return r, fmt.Errorf("graph has %d integrity errors", errs)
+	}
+	return r, nil
+}
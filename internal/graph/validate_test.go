@@ -0,0 +1,66 @@
+package graph
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestValidateDefaultGraph проверяет, что встроенный граф корректен.
+func TestValidateDefaultGraph(t *testing.T) {
+	r := Validate(DefaultGraph())
+	if len(r.Findings) != 0 {
+		t.Fatalf("ожидалось отсутствие проблем: %+v", r.Findings)
+	}
+}
+
+// TestValidate проверяет обнаружение всех видов проблем.
+func TestValidate(t *testing.T) {
+	g := Graph{
+		Systems: []System{
+			{ID: 1, Name: "Alpha", RegionID: 1, ConstellationID: 10},
+			{ID: 2, Name: "Beta", RegionID: 1, ConstellationID: 10},
+			{ID: 2, Name: "Beta copy", RegionID: 1, ConstellationID: 10},
+			{ID: 3, Name: "alpha", RegionID: 1, ConstellationID: 10},
+			{ID: 4, Name: "Lost", RegionID: 9, ConstellationID: 99},
+		},
+		Connections: [][2]int{{1, 2}, {2, 1}, {2, 3}, {3, 3}, {3, 42}},
+		Regions:     map[int]string{1: "Demo"},
+		Constellations: map[int]string{
+			10: "Demo Constellation",
+		},
+	}
+	r := Validate(g)
+	var codes []string
+	for _, f := range r.Findings {
+		codes = append(codes, string(f.Severity)+" "+f.Code)
+	}
+	want := []string{
+		"error duplicate-id",
+		"error unknown-region",
+		"warning unknown-constellation",
+		"error duplicate-name",
+		"warning duplicate-connection",
+		"warning self-loop",
+		"error dangling-connection",
+		"warning disconnected",
+	}
+	if !reflect.DeepEqual(codes, want) {
+		t.Fatalf("неверные проблемы:\nполучено %v\nожидалось %v", codes, want)
+	}
+	if ids := r.Findings[3].SystemIDs; !reflect.DeepEqual(ids, []int{1, 3}) {
+		t.Errorf("неверные системы с одинаковым названием: %v", ids)
+	}
+	if ids := r.Findings[7].SystemIDs; !reflect.DeepEqual(ids, []int{4}) {
+		t.Errorf("неверные недостижимые системы: %v", ids)
+	}
+	if !r.HasErrors() || r.Count(SeverityError) != 4 || r.Count(SeverityWarning) != 4 {
+		t.Errorf("неверные счётчики: %d ошибок, %d предупреждений", r.Count(SeverityError), r.Count(SeverityWarning))
+	}
+
+	if _, err := Check(g, false, "test"); err != nil {
+		t.Errorf("нестрогий режим не должен отклонять граф: %v", err)
+	}
+	if _, err := Check(g, true, "test"); err == nil {
+		t.Error("строгий режим должен отклонять граф с ошибками")
+	}
+}
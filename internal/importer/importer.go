@@ -13,6 +13,7 @@ type ESIClient interface {
 	Systems(ctx context.Context) ([]esi.System, error)
 	Connections(ctx context.Context, systems []esi.System) ([][2]int32, error)
 	RegionName(ctx context.Context, id int32) (string, error)
+	FactionOwners(ctx context.Context) (map[int32]int32, error)
 }
 
 // BuildGraph загружает данные из ESI и формирует граф карты.
@@ -25,15 +26,31 @@ func BuildGraph(ctx context.Context, c ESIClient) (graph.Graph, error) {
 	if err != nil {
 		return graph.Graph{}, err
 	}
+	owners, err := c.FactionOwners(ctx)
+	if err != nil {
+		return graph.Graph{}, err
+	}
 	gSystems := make([]graph.System, 0, len(systems))
 	regions := make(map[int]string)
+	constellations := make(map[int]string)
 	for _, s := range systems {
-		gSystems = append(gSystems, graph.System{
-			ID:       int(s.ID),
-			Name:     s.Name,
-			Security: s.Security,
-			RegionID: int(s.RegionID),
-		})
+		sys := graph.System{
+			ID:              int(s.ID),
+			Name:            s.Name,
+			Security:        s.Security,
+			RegionID:        int(s.RegionID),
+			ConstellationID: int(s.ConstellationID),
+			X:               s.X,
+			Y:               s.Y,
+			Z:               s.Z,
+			FactionID:       int(owners[s.ID]),
+			Stations:        s.Stations,
+		}
+		sys.MapX, sys.MapY = graph.ProjectMap(s.X, s.Z)
+		gSystems = append(gSystems, sys)
+		if s.ConstellationID != 0 {
+			constellations[int(s.ConstellationID)] = s.ConstellationName
+		}
 		if _, ok := regions[int(s.RegionID)]; !ok {
 			name, err := c.RegionName(ctx, s.RegionID)
 			if err != nil {
@@ -47,5 +64,5 @@ func BuildGraph(ctx context.Context, c ESIClient) (graph.Graph, error) {
 		gConns = append(gConns, [2]int{int(p[0]), int(p[1])})
 	}
 	log.Printf("importer: imported %d systems and %d connections", len(gSystems), len(gConns))
-	return graph.Graph{Systems: gSystems, Connections: gConns, Regions: regions}, nil
+	return graph.Graph{Systems: gSystems, Connections: gConns, Regions: regions, Constellations: constellations}, nil
 }
@@ -11,8 +11,8 @@ type fakeESI struct{}
 
 func (fakeESI) Systems(ctx context.Context) ([]esi.System, error) {
 	return []esi.System{
-		{ID: 1, Name: "Alpha", Security: 0.5, RegionID: 10},
-		{ID: 2, Name: "Beta", Security: 0.6, RegionID: 10},
+		{ID: 1, Name: "Alpha", Security: 0.5, RegionID: 10, ConstellationID: 20, ConstellationName: "Pair", X: 1e16, Z: 2e16, Stations: 3},
+		{ID: 2, Name: "Beta", Security: 0.6, RegionID: 10, ConstellationID: 20, ConstellationName: "Pair"},
 	}, nil
 }
 
@@ -24,6 +24,10 @@ func (fakeESI) RegionName(ctx context.Context, id int32) (string, error) {
 	return map[int32]string{10: "Demo"}[id], nil
 }
 
+func (fakeESI) FactionOwners(ctx context.Context) (map[int32]int32, error) {
+	return map[int32]int32{1: 500001}, nil
+}
+
 func TestBuildGraph(t *testing.T) {
 	g, err := BuildGraph(context.Background(), fakeESI{})
 	if err != nil {
@@ -38,4 +42,14 @@ func TestBuildGraph(t *testing.T) {
 	if g.Regions[10] != "Demo" {
 		t.Fatalf("unexpected region map: %#v", g.Regions)
 	}
+	if g.Constellations[20] != "Pair" {
+		t.Fatalf("unexpected constellation map: %#v", g.Constellations)
+	}
+	a := g.Systems[0]
+	if a.ConstellationID != 20 || a.FactionID != 500001 || a.Stations != 3 || a.MapX != 1e16 || a.MapY != -2e16 {
+		t.Fatalf("unexpected system metadata: %+v", a)
+	}
+	if g.Systems[1].FactionID != 0 {
+		t.Fatalf("unexpected faction for Beta: %+v", g.Systems[1])
+	}
 }
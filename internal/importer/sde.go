@@ -0,0 +1,343 @@
+package importer
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/tkhamez/eve-route-go/internal/graph"
+)
+
+// Файлы карты из статической выгрузки данных (SDE) без расширения:
+// каждый может быть в формате JSONL (.jsonl) или YAML (.yaml, .yml).
+const (
+	sdeRegionsFile        = "mapRegions"
+	sdeConstellationsFile = "mapConstellations"
+	sdeSystemsFile        = "mapSolarSystems"
+	sdeStargatesFile      = "mapStargates"
+	// sdeStationsFile необязателен: без него системы остаются без станций.
+	sdeStationsFile = "npcStations"
+)
+
+// sdeExtensions — поддерживаемые расширения файлов выгрузки.
+var sdeExtensions = []string{".jsonl", ".yaml", ".yml"}
+
+// sdeName — локализованное название из SDE. Поддерживаются как объект
+// с переводами ({"en": "..."}), так и простая строка.
+type sdeName string
+
+func (n *sdeName) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*n = sdeName(s)
+		return nil
+	}
+	var m map[string]string
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	*n = sdeName(m["en"])
+	return nil
+}
+
+func (n *sdeName) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*n = sdeName(value.Value)
+		return nil
+	}
+	var m map[string]string
+	if err := value.Decode(&m); err != nil {
+		return err
+	}
+	*n = sdeName(m["en"])
+	return nil
+}
+
+type sdePosition struct {
+	X float64 `json:"x" yaml:"x"`
+	Y float64 `json:"y" yaml:"y"`
+	Z float64 `json:"z" yaml:"z"`
+}
+
+type sdeRegion struct {
+	ID        int     `json:"_key" yaml:"_key"`
+	Name      sdeName `json:"name" yaml:"name"`
+	FactionID int     `json:"factionID" yaml:"factionID"`
+}
+
+type sdeConstellation struct {
+	ID        int     `json:"_key" yaml:"_key"`
+	Name      sdeName `json:"name" yaml:"name"`
+	RegionID  int     `json:"regionID" yaml:"regionID"`
+	FactionID int     `json:"factionID" yaml:"factionID"`
+}
+
+type sdeSystem struct {
+	ID              int          `json:"_key" yaml:"_key"`
+	Name            sdeName      `json:"name" yaml:"name"`
+	RegionID        int          `json:"regionID" yaml:"regionID"`
+	ConstellationID int          `json:"constellationID" yaml:"constellationID"`
+	SecurityStatus  float64      `json:"securityStatus" yaml:"securityStatus"`
+	FactionID       int          `json:"factionID" yaml:"factionID"`
+	Position        sdePosition  `json:"position" yaml:"position"`
+	Position2D      *sdePosition `json:"position2D" yaml:"position2D"`
+}
+
+type sdeStation struct {
+	SolarSystemID int `json:"solarSystemID" yaml:"solarSystemID"`
+}
+
+type sdeStargate struct {
+	ID            int `json:"_key" yaml:"_key"`
+	SolarSystemID int `json:"solarSystemID" yaml:"solarSystemID"`
+	Destination   struct {
+		SolarSystemID int `json:"solarSystemID" yaml:"solarSystemID"`
+	} `json:"destination" yaml:"destination"`
+}
+
+// BuildGraphFromSDE формирует граф карты из локально скачанной выгрузки SDE.
+// path указывает на ZIP-архив или каталог с файлами JSONL или YAML; файлы могут
+// лежать в любом подкаталоге. Системы и соединения сортируются по ID, поэтому
+// повторный импорт одной и той же выгрузки даёт идентичный граф.
+func BuildGraphFromSDE(path string) (graph.Graph, error) {
+	fsys, closeFn, err := openSDE(path)
+	if err != nil {
+		return graph.Graph{}, err
+	}
+	defer closeFn()
+	return BuildGraphFromSDEFS(fsys)
+}
+
+// BuildGraphFromSDEFS формирует граф из файловой системы с файлами SDE.
+func BuildGraphFromSDEFS(fsys fs.FS) (graph.Graph, error) {
+	files, err := findSDEFiles(fsys)
+	if err != nil {
+		return graph.Graph{}, err
+	}
+	g := graph.Graph{Regions: map[int]string{}, Constellations: map[int]string{}}
+	// фракция системы наследуется от созвездия, а созвездия — от региона
+	regionFactions := map[int]int{}
+	constellationFactions := map[int]int{}
+
+	err = readSDE(fsys, files[sdeRegionsFile], func(r sdeRegion) {
+		g.Regions[r.ID] = string(r.Name)
+		regionFactions[r.ID] = r.FactionID
+	})
+	if err != nil {
+		return graph.Graph{}, err
+	}
+	err = readSDE(fsys, files[sdeConstellationsFile], func(c sdeConstellation) {
+		g.Constellations[c.ID] = string(c.Name)
+		constellationFactions[c.ID] = c.FactionID
+	})
+	if err != nil {
+		return graph.Graph{}, err
+	}
+	stations := map[int]int{}
+	if name, ok := files[sdeStationsFile]; ok {
+		err = readSDE(fsys, name, func(s sdeStation) {
+			stations[s.SolarSystemID]++
+		})
+		if err != nil {
+			return graph.Graph{}, err
+		}
+	}
+	err = readSDE(fsys, files[sdeSystemsFile], func(s sdeSystem) {
+		sys := graph.System{
+			ID:              s.ID,
+			Name:            string(s.Name),
+			Security:        s.SecurityStatus,
+			RegionID:        s.RegionID,
+			ConstellationID: s.ConstellationID,
+			X:               s.Position.X,
+			Y:               s.Position.Y,
+			Z:               s.Position.Z,
+			FactionID:       s.FactionID,
+			Stations:        stations[s.ID],
+		}
+		if sys.FactionID == 0 {
+			sys.FactionID = constellationFactions[s.ConstellationID]
+		}
+		if sys.FactionID == 0 {
+			sys.FactionID = regionFactions[s.RegionID]
+		}
+		if s.Position2D != nil {
+			sys.MapX, sys.MapY = s.Position2D.X, s.Position2D.Y
+		} else {
+			sys.MapX, sys.MapY = graph.ProjectMap(sys.X, sys.Z)
+		}
+		g.Systems = append(g.Systems, sys)
+	})
+	if err != nil {
+		return graph.Graph{}, err
+	}
+	seen := map[[2]int]bool{}
+	err = readSDE(fsys, files[sdeStargatesFile], func(s sdeStargate) {
+		pair := [2]int{s.SolarSystemID, s.Destination.SolarSystemID}
+		if pair[0] > pair[1] {
+			pair[0], pair[1] = pair[1], pair[0]
+		}
+		if !seen[pair] {
+			seen[pair] = true
+			g.Connections = append(g.Connections, pair)
+		}
+	})
+	if err != nil {
+		return graph.Graph{}, err
+	}
+
+	sort.Slice(g.Systems, func(i, j int) bool { return g.Systems[i].ID < g.Systems[j].ID })
+	sort.Slice(g.Connections, func(i, j int) bool {
+		a, b := g.Connections[i], g.Connections[j]
+		if a[0] != b[0] {
+			return a[0] < b[0]
+		}
+		return a[1] < b[1]
+	})
+	log.Printf("importer: imported %d systems, %d connections, %d regions and %d constellations from SDE",
+		len(g.Systems), len(g.Connections), len(g.Regions), len(g.Constellations))
+	return g, nil
+}
+
+// openSDE открывает ZIP-архив или каталог выгрузки.
+func openSDE(p string) (fs.FS, func(), error) {
+	info, err := os.Stat(p)
+	if err != nil {
+		return nil, nil, err
+	}
+	if info.IsDir() {
+		return os.DirFS(p), func() {}, nil
+	}
+	zr, err := zip.OpenReader(p)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open SDE archive: %w", err)
+	}
+	return zr, func() {
+		if cErr := zr.Close(); cErr != nil {
+			log.Printf("importer: close SDE archive: %v", cErr)
+		}
+	}, nil
+}
+
+// findSDEFiles находит пути ко всем необходимым файлам карты. Файлы с
+// неподдерживаемым расширением (например, JSON или CSV) не учитываются.
+func findSDEFiles(fsys fs.FS) (map[string]string, error) {
+	files := map[string]string{}
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !isSDEExtension(path.Ext(p)) {
+			return nil
+		}
+		switch name := strings.TrimSuffix(path.Base(p), path.Ext(p)); name {
+		case sdeRegionsFile, sdeConstellationsFile, sdeSystemsFile, sdeStargatesFile, sdeStationsFile:
+			if _, ok := files[name]; !ok {
+				files[name] = p
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	var missing []string
+	for _, name := range []string{sdeRegionsFile, sdeConstellationsFile, sdeSystemsFile, sdeStargatesFile} {
+		if _, ok := files[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("SDE files not found (%s): %s",
+			strings.Join(sdeExtensions, ", "), strings.Join(missing, ", "))
+	}
+	return files, nil
+}
+
+func isSDEExtension(ext string) bool {
+	for _, e := range sdeExtensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// readSDE читает файл выгрузки в формате, определяемом по расширению.
+func readSDE[T any](fsys fs.FS, name string, fn func(T)) error {
+	if path.Ext(name) == ".jsonl" {
+		return readJSONL(fsys, name, fn)
+	}
+	return readYAML(fsys, name, fn)
+}
+
+// readJSONL читает файл, в котором каждая строка — отдельный JSON-объект.
+func readJSONL[T any](fsys fs.FS, name string, fn func(T)) error {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	dec := json.NewDecoder(f)
+	for line := 1; ; line++ {
+		var v T
+		if err := dec.Decode(&v); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("%s: record %d: %w", name, line, err)
+		}
+		fn(v)
+	}
+}
+
+// readYAML читает файл YAML, в котором записи — значения словаря с ID в
+// качестве ключа. Ключ передаётся в запись как поле _key, как в JSONL.
+// Записи обрабатываются в порядке следования в файле.
+func readYAML[T any](fsys fs.FS, name string, fn func(T)) error {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var doc yaml.Node
+	if err := yaml.NewDecoder(f).Decode(&doc); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	root := &doc
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("%s: expected a mapping of records at line %d", name, root.Line)
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		if value.Kind != yaml.MappingNode {
+			return fmt.Errorf("%s: record %s: expected a mapping at line %d", name, key.Value, value.Line)
+		}
+		value.Content = append(value.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "_key"},
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: key.Value},
+		)
+		var v T
+		if err := value.Decode(&v); err != nil {
+			return fmt.Errorf("%s: record %s: %w", name, key.Value, err)
+		}
+		fn(v)
+	}
+	return nil
+}
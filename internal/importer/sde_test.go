@@ -0,0 +1,157 @@
+package importer
+
+import (
+	"archive/zip"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tkhamez/eve-route-go/internal/graph"
+)
+
+const sdeFixture = "testdata/sde"
+
+func TestBuildGraphFromSDE(t *testing.T) {
+	g, err := BuildGraphFromSDE(sdeFixture)
+	if err != nil {
+		t.Fatalf("build graph: %v", err)
+	}
+	if len(g.Systems) != 4 {
+		t.Fatalf("expected 4 systems, got %d", len(g.Systems))
+	}
+	tanoo := g.Systems[0]
+	if tanoo.ID != 30000001 || tanoo.Name != "Tanoo" || tanoo.RegionID != 10000001 ||
+		tanoo.ConstellationID != 20000001 || tanoo.Security != 0.858 || tanoo.X != -8.85e16 {
+		t.Fatalf("unexpected system: %+v", tanoo)
+	}
+	want := [][2]int{{30000001, 30000002}, {30000001, 30000003}, {30000003, 30000142}}
+	if !reflect.DeepEqual(g.Connections, want) {
+		t.Fatalf("unexpected connections: %v", g.Connections)
+	}
+	if g.Regions[10000002] != "The Forge" || g.Constellations[20000002] != "Kimotoro" {
+		t.Fatalf("unexpected names: %v %v", g.Regions, g.Constellations)
+	}
+}
+
+func TestBuildGraphFromSDEMetadata(t *testing.T) {
+	g, err := BuildGraphFromSDE(sdeFixture)
+	if err != nil {
+		t.Fatalf("build graph: %v", err)
+	}
+	byName := map[string]graph.System{}
+	for _, s := range g.Systems {
+		byName[s.Name] = s
+	}
+	// фракция созвездия San Matar
+	if s := byName["Tanoo"]; s.FactionID != 500007 || s.Stations != 1 || s.MapX != -8.85e16 || s.MapY != 4.45e16 {
+		t.Fatalf("unexpected Tanoo: %+v", s)
+	}
+	// фракция региона The Forge, проекция из position2D
+	if s := byName["Jita"]; s.FactionID != 500001 || s.Stations != 2 || s.MapY != -1.17e17 {
+		t.Fatalf("unexpected Jita: %+v", s)
+	}
+	if s := byName["Lashesih"]; s.Stations != 0 || !s.HasPosition() {
+		t.Fatalf("unexpected Lashesih: %+v", s)
+	}
+}
+
+func TestBuildGraphFromSDEZip(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "sde.zip")
+	f, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	err = fs.WalkDir(os.DirFS(sdeFixture), ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(filepath.Join(sdeFixture, p))
+		if err != nil {
+			return err
+		}
+		w, err := zw.Create("sde/" + p)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	fromZip, err := BuildGraphFromSDE(archive)
+	if err != nil {
+		t.Fatalf("build graph from zip: %v", err)
+	}
+	fromDir, err := BuildGraphFromSDE(sdeFixture)
+	if err != nil {
+		t.Fatalf("build graph from dir: %v", err)
+	}
+	if !reflect.DeepEqual(fromZip, fromDir) {
+		t.Fatalf("zip and directory imports differ")
+	}
+}
+
+func TestBuildGraphFromSDEMissingFiles(t *testing.T) {
+	if _, err := BuildGraphFromSDE(t.TempDir()); err == nil {
+		t.Fatalf("expected error for empty directory")
+	}
+}
+
+func TestBuildGraphFromSDEYAML(t *testing.T) {
+	fromYAML, err := BuildGraphFromSDE("testdata/sde-yaml")
+	if err != nil {
+		t.Fatalf("build graph from YAML: %v", err)
+	}
+	fromJSONL, err := BuildGraphFromSDE(sdeFixture)
+	if err != nil {
+		t.Fatalf("build graph from JSONL: %v", err)
+	}
+	if !reflect.DeepEqual(fromYAML, fromJSONL) {
+		t.Fatalf("YAML and JSONL imports differ:\n%+v\n%+v", fromYAML, fromJSONL)
+	}
+}
+
+func TestBuildGraphFromSDEUnsupportedFormat(t *testing.T) {
+	// все файлы, кроме mapStargates, в поддерживаемом формате
+	dir := t.TempDir()
+	for _, name := range []string{"mapRegions.jsonl", "mapConstellations.yaml", "mapSolarSystems.yml", "mapStargates.json"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := BuildGraphFromSDE(dir)
+	if err == nil || !strings.Contains(err.Error(), "mapStargates") || strings.Contains(err.Error(), "mapRegions") {
+		t.Fatalf("expected missing mapStargates, got %v", err)
+	}
+}
+
+func TestBuildGraphFromSDEInvalidYAML(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"mapRegions.yaml":        "10000001:\n  name: Derelik\n",
+		"mapConstellations.yaml": "",
+		"mapSolarSystems.yaml":   "- 30000001\n",
+		"mapStargates.yaml":      "",
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := BuildGraphFromSDE(dir)
+	if err == nil || !strings.Contains(err.Error(), "mapSolarSystems.yaml") {
+		t.Fatalf("expected error for mapSolarSystems.yaml, got %v", err)
+	}
+}
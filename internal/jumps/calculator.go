@@ -3,40 +3,115 @@ package jumps
 import (
 	"container/list"
 	"errors"
-	"github.com/tkhamez/eve-route-go/internal/graph"
+	"fmt"
 	"log"
+	"sync"
+
+	"github.com/tkhamez/eve-route-go/internal/graph"
+	routepkg "github.com/tkhamez/eve-route-go/internal/route"
 )
 
+// ErrNotReachable возвращается, если между системами нет пути.
+var ErrNotReachable = errors.New("route not found")
+
+// Hop — один переход пути.
+type Hop struct {
+	From string                `json:"from"`
+	To   string                `json:"to"`
+	Type routepkg.WaypointType `json:"type"`
+}
+
+type edge struct {
+	to  int
+	typ routepkg.WaypointType
+}
+
 // Calculator рассчитывает количество прыжков между системами.
 type Calculator struct {
 	helper *graph.Helper
-	adj    map[int][]int
+	adj    map[int][]edge
+	names  map[int]string
 }
 
 // NewCalculator создаёт новый калькулятор на основе графа.
+// Учитываются только звёздные врата.
 func NewCalculator(g graph.Graph) *Calculator {
-	h := graph.NewHelper(g)
-	adj := map[int][]int{}
+	adj := map[int][]edge{}
 	for _, c := range g.Connections {
-		adj[c[0]] = append(adj[c[0]], c[1])
-		adj[c[1]] = append(adj[c[1]], c[0])
+		adj[c[0]] = append(adj[c[0]], edge{to: c[1], typ: routepkg.TypeStargate})
+		adj[c[1]] = append(adj[c[1]], edge{to: c[0], typ: routepkg.TypeStargate})
+	}
+	return newCalculator(g, adj)
+}
+
+// NewNetworkCalculator создаёт калькулятор по всей сети маршрутизатора r: кроме
+// звёздных врат учитываются его Ansiblex и временные соединения.
+func NewNetworkCalculator(r *routepkg.Route) *Calculator {
+	adj := map[int][]edge{}
+	for _, n := range r.Nodes() {
+		for _, c := range n.Connections() {
+			adj[n.Value.ID] = append(adj[n.Value.ID], edge{to: c.Node.Value.ID, typ: c.Type})
+		}
+	}
+	return newCalculator(r.Graph(), adj)
+}
+
+// Network отдаёт калькулятор по сети текущего маршрутизатора src и строит его
+// заново после замены маршрутизатора (например, после Cache.Rebuild).
+type Network struct {
+	src routepkg.Source
+
+	mu sync.Mutex
+	// route — маршрутизатор, по которому построен calc.
+	route *routepkg.Route
+	calc  *Calculator
+}
+
+// NewNetwork создаёт калькулятор по сети маршрутизатора src.
+func NewNetwork(src routepkg.Source) *Network {
+	return &Network{src: src}
+}
+
+// Calculator возвращает калькулятор для текущего маршрутизатора.
+func (n *Network) Calculator() *Calculator {
+	r := n.src.Route()
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.route != r {
+		n.route, n.calc = r, NewNetworkCalculator(r)
 	}
-	return &Calculator{helper: h, adj: adj}
+	return n.calc
+}
+
+func newCalculator(g graph.Graph, adj map[int][]edge) *Calculator {
+	names := make(map[int]string, len(g.Systems))
+	for _, s := range g.Systems {
+		names[s.ID] = s.Name
+	}
+	return &Calculator{helper: graph.NewHelper(g), adj: adj, names: names}
 }
 
 // Between возвращает минимальное число прыжков от from до to.
 func (c *Calculator) Between(from, to string) (int, error) {
 	log.Printf("jumps: %s -> %s", from, to)
-	start := c.helper.FindSystemByName(from)
-	end := c.helper.FindSystemByName(to)
-	if start == nil || end == nil {
-		return 0, errors.New("system not found")
+	path, err := c.Path(from, to)
+	if err != nil {
+		return 0, err
+	}
+	return len(path), nil
+}
+
+// Path возвращает один из кратчайших путей от from до to
+// или ErrNotReachable, если пути нет.
+func (c *Calculator) Path(from, to string) ([]Hop, error) {
+	start, end, err := c.lookup(from, to)
+	if err != nil {
+		return nil, err
 	}
 	if start.ID == end.ID {
-		return 0, nil
+		return []Hop{}, nil
 	}
-	visited := map[int]bool{start.ID: true}
-	dist := map[int]int{start.ID: 0}
+	parent := map[int]edge{start.ID: {}}
 	q := list.New()
 	q.PushBack(start.ID)
 	for q.Len() > 0 {
@@ -44,15 +119,84 @@ func (c *Calculator) Between(from, to string) (int, error) {
 		q.Remove(e)
 		cur := e.Value.(int)
 		if cur == end.ID {
-			return dist[cur], nil
+			break
+		}
+		for _, n := range c.adj[cur] {
+			if _, ok := parent[n.to]; !ok {
+				// в parent хранится предыдущая система и тип соединения
+				parent[n.to] = edge{to: cur, typ: n.typ}
+				q.PushBack(n.to)
+			}
+		}
+	}
+	if _, ok := parent[end.ID]; !ok {
+		return nil, ErrNotReachable
+	}
+	var path []Hop
+	for cur := end.ID; cur != start.ID; {
+		p := parent[cur]
+		path = append(path, Hop{From: c.names[p.to], To: c.names[cur], Type: p.typ})
+		cur = p.to
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path, nil
+}
+
+// Matrix возвращает число прыжков от каждой системы from до каждой системы to;
+// -1 означает, что система недостижима. Для каждой системы from выполняется
+// один обход в ширину.
+func (c *Calculator) Matrix(from, to []string) ([][]int, error) {
+	targets := make([]int, len(to))
+	for i, name := range to {
+		s := c.helper.FindSystemByName(name)
+		if s == nil {
+			return nil, fmt.Errorf("system %q not found", name)
 		}
+		targets[i] = s.ID
+	}
+	res := make([][]int, len(from))
+	for i, name := range from {
+		s := c.helper.FindSystemByName(name)
+		if s == nil {
+			return nil, fmt.Errorf("system %q not found", name)
+		}
+		dist := c.distances(s.ID)
+		res[i] = make([]int, len(targets))
+		for j, t := range targets {
+			if d, ok := dist[t]; ok {
+				res[i][j] = d
+			} else {
+				res[i][j] = -1
+			}
+		}
+	}
+	return res, nil
+}
+
+// distances возвращает число прыжков от системы до всех достижимых систем.
+func (c *Calculator) distances(from int) map[int]int {
+	dist := map[int]int{from: 0}
+	queue := []int{from}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
 		for _, n := range c.adj[cur] {
-			if !visited[n] {
-				visited[n] = true
-				dist[n] = dist[cur] + 1
-				q.PushBack(n)
+			if _, ok := dist[n.to]; !ok {
+				dist[n.to] = dist[cur] + 1
+				queue = append(queue, n.to)
 			}
 		}
 	}
-	return 0, errors.New("route not found")
+	return dist
+}
+
+func (c *Calculator) lookup(from, to string) (*graph.System, *graph.System, error) {
+	start := c.helper.FindSystemByName(from)
+	end := c.helper.FindSystemByName(to)
+	if start == nil || end == nil {
+		return nil, nil, errors.New("system not found")
+	}
+	return start, end, nil
 }
@@ -1,8 +1,13 @@
 package jumps
 
 import (
-	"github.com/tkhamez/eve-route-go/internal/graph"
+	"errors"
+	"reflect"
 	"testing"
+
+	dbstore "github.com/tkhamez/eve-route-go/internal/dbstore"
+	"github.com/tkhamez/eve-route-go/internal/graph"
+	routepkg "github.com/tkhamez/eve-route-go/internal/route"
 )
 
 func TestCalculator_Between(t *testing.T) {
@@ -54,3 +59,104 @@ func TestCalculator_Between(t *testing.T) {
 		}
 	})
 }
+
+// networkGraph — цепочка A-B-C-D-E, Ansiblex между A и D, временное соединение между D и E.
+func networkGraph() (graph.Graph, dbstore.Store) {
+	g := graph.Graph{
+		Systems: []graph.System{
+			{ID: 1, Name: "A", RegionID: 1},
+			{ID: 2, Name: "B", RegionID: 1},
+			{ID: 3, Name: "C", RegionID: 1},
+			{ID: 4, Name: "D", RegionID: 1},
+			{ID: 5, Name: "E", RegionID: 1},
+			{ID: 6, Name: "F", RegionID: 1},
+		},
+		Connections: [][2]int{{1, 2}, {2, 3}, {3, 4}, {4, 5}},
+		Regions:     map[int]string{1: "R"},
+	}
+	store := dbstore.NewMemory(
+		[]dbstore.Ansiblex{
+			{ID: 1, Name: graph.AnsiblexName("A", "D", "JB"), SolarSystemID: 1},
+			{ID: 2, Name: graph.AnsiblexName("D", "A", "JB"), SolarSystemID: 4},
+		},
+		[]dbstore.TemporaryConnection{{System1ID: 5, System2ID: 6}},
+		nil,
+	)
+	return g, store
+}
+
+func networkRoute(t *testing.T, g graph.Graph, store dbstore.Store) *routepkg.Route {
+	t.Helper()
+	r, err := routepkg.NewRouteFromGraph(g, store, nil, nil)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	return r
+}
+
+func TestCalculator_Network(t *testing.T) {
+	g, store := networkGraph()
+	c := NewNetwork(networkRoute(t, g, store)).Calculator()
+	path, err := c.Path("A", "F")
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	want := []Hop{
+		{From: "A", To: "D", Type: routepkg.TypeAnsiblex},
+		{From: "D", To: "E", Type: routepkg.TypeStargate},
+		{From: "E", To: "F", Type: routepkg.TypeTemporary},
+	}
+	if !reflect.DeepEqual(path, want) {
+		t.Fatalf("неверный путь: %+v", path)
+	}
+	if jumps, _ := c.Between("A", "E"); jumps != 2 {
+		t.Fatalf("ожидалось 2 прыжка, получено %d", jumps)
+	}
+	// только врата: F недостижима, до E четыре прыжка
+	gates := NewCalculator(g)
+	if _, err := gates.Path("A", "F"); !errors.Is(err, ErrNotReachable) {
+		t.Fatal("ожидалась ошибка для недостижимого маршрута")
+	}
+	if path, _ := gates.Path("A", "E"); len(path) != 4 || path[0].Type != routepkg.TypeStargate {
+		t.Fatalf("неверный путь по вратам: %+v", path)
+	}
+}
+
+func TestCalculator_Matrix(t *testing.T) {
+	g, store := networkGraph()
+	c := NewNetwork(networkRoute(t, g, store)).Calculator()
+	m, err := c.Matrix([]string{"A", "c"}, []string{"A", "E", "F"})
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if want := [][]int{{0, 2, 3}, {2, 2, 3}}; !reflect.DeepEqual(m, want) {
+		t.Fatalf("неверная матрица: %v", m)
+	}
+	m, _ = NewCalculator(g).Matrix([]string{"A"}, []string{"F"})
+	if m[0][0] != -1 {
+		t.Fatalf("ожидалось -1 для недостижимой системы, получено %d", m[0][0])
+	}
+	if _, err := c.Matrix([]string{"A"}, []string{"Nowhere"}); err == nil {
+		t.Fatal("ожидалась ошибка для неизвестной системы")
+	}
+}
+
+func TestNetwork(t *testing.T) {
+	g, store := networkGraph()
+	cache := routepkg.NewCache(networkRoute(t, g, store), 1)
+	n := NewNetwork(cache)
+	c := n.Calculator()
+	if jumps, _ := c.Between("A", "F"); jumps != 3 {
+		t.Fatalf("ожидалось 3 прыжка, получено %d", jumps)
+	}
+	if n.Calculator() != c {
+		t.Fatal("калькулятор должен строиться один раз для маршрутизатора")
+	}
+	// после перестроения без Ansiblex и временных соединений F недостижима
+	if err := cache.Rebuild(g, dbstore.NewMemory(nil, nil, nil)); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if _, err := n.Calculator().Path("A", "F"); !errors.Is(err, ErrNotReachable) {
+		t.Fatalf("ожидалась ErrNotReachable, получено %v", err)
+	}
+}
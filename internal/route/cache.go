@@ -0,0 +1,165 @@
+package route
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	dbstore "github.com/tkhamez/eve-route-go/internal/dbstore"
+	"github.com/tkhamez/eve-route-go/internal/graph"
+)
+
+// Cache хранит результаты поиска маршрутов по принципу LRU.
+// Ключ состоит из начальной и конечной систем и нормализованных параметров поиска.
+// Все записи сбрасываются при изменении данных Ansiblex или временных соединений
+// (см. Invalidate): версия графа увеличивается, и результаты поисков,
+// начатых до изменения, в кэш уже не попадают.
+//
+// Возвращаемые срезы общие для всех вызывающих и не должны изменяться.
+type Cache struct {
+	route    *Route
+	capacity int
+
+	// rebuildMu упорядочивает перестроения: каждое читает хранилище после
+	// предыдущего, поэтому последним устанавливается самый свежий маршрутизатор.
+	rebuildMu sync.Mutex
+
+	mu      sync.Mutex
+	version uint64
+	entries map[string]*list.Element
+	order   *list.List // от последнего использованного к самому давнему
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+type cacheEntry struct {
+	key   string
+	paths [][]Waypoint
+}
+
+// CacheStats — счётчики кэша для мониторинга.
+type CacheStats struct {
+	Hits     uint64 `json:"hits"`
+	Misses   uint64 `json:"misses"`
+	Entries  int    `json:"entries"`
+	Capacity int    `json:"capacity"`
+	Version  uint64 `json:"version"`
+}
+
+// NewCache создаёт кэш на capacity записей поверх маршрутизатора r.
+func NewCache(r *Route, capacity int) *Cache {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &Cache{
+		route:    r,
+		capacity: capacity,
+		entries:  map[string]*list.Element{},
+		order:    list.New(),
+	}
+}
+
+// FindWithOptions возвращает маршрут из кэша или ищет его и сохраняет результат.
+// Ошибки (например, отмена контекста) не кэшируются.
+func (c *Cache) FindWithOptions(ctx context.Context, from, to string, opts FindOptions) ([][]Waypoint, error) {
+	key := strings.ToLower(from) + "\x00" + strings.ToLower(to) + "\x00" + opts.Key()
+
+	c.mu.Lock()
+	if el, ok := c.entries[key]; ok {
+		c.order.MoveToFront(el)
+		paths := el.Value.(*cacheEntry).paths
+		c.mu.Unlock()
+		c.hits.Add(1)
+		return paths, nil
+	}
+	version := c.version
+	route := c.route
+	c.mu.Unlock()
+	c.misses.Add(1)
+
+	paths, err := route.FindWithOptions(ctx, from, to, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// данные изменились во время поиска — результат может быть устаревшим
+	if version != c.version {
+		return paths, nil
+	}
+	if el, ok := c.entries[key]; ok {
+		c.order.MoveToFront(el)
+		return paths, nil
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, paths: paths})
+	for c.order.Len() > c.capacity {
+		last := c.order.Back()
+		c.order.Remove(last)
+		delete(c.entries, last.Value.(*cacheEntry).key)
+	}
+	return paths, nil
+}
+
+// Find возвращает маршрут без дополнительных параметров поиска.
+func (c *Cache) Find(ctx context.Context, from, to string) ([][]Waypoint, error) {
+	return c.FindWithOptions(ctx, from, to, FindOptions{})
+}
+
+// Invalidate увеличивает версию графа и удаляет все записи.
+// Вызывается при любом изменении Ansiblex или временных соединений.
+func (c *Cache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.invalidateLocked()
+}
+
+// SetRoute заменяет маршрутизатор (например, после перестроения графа) и сбрасывает кэш.
+func (c *Cache) SetRoute(r *Route) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.route = r
+	c.invalidateLocked()
+}
+
+// Rebuild строит маршрутизатор заново по графу g и соединениям из store
+// и заменяет им текущий (см. SetRoute). При ошибке текущий маршрутизатор остаётся.
+func (c *Cache) Rebuild(g graph.Graph, store dbstore.ConnectionStore) error {
+	c.rebuildMu.Lock()
+	defer c.rebuildMu.Unlock()
+	r, err := NewRouteFromGraph(g, store, nil, nil)
+	if err != nil {
+		return err
+	}
+	c.SetRoute(r)
+	return nil
+}
+
+// Route возвращает текущий маршрутизатор.
+func (c *Cache) Route() *Route {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.route
+}
+
+func (c *Cache) invalidateLocked() {
+	c.version++
+	c.entries = map[string]*list.Element{}
+	c.order.Init()
+}
+
+// Stats возвращает текущие счётчики кэша.
+func (c *Cache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return CacheStats{
+		Hits:     c.hits.Load(),
+		Misses:   c.misses.Load(),
+		Entries:  c.order.Len(),
+		Capacity: c.capacity,
+		Version:  c.version,
+	}
+}
@@ -0,0 +1,88 @@
+package route
+
+import (
+	"context"
+	"testing"
+
+	dbstore "github.com/tkhamez/eve-route-go/internal/dbstore"
+)
+
+// TestCache проверяет попадания, промахи, вытеснение и сброс кэша.
+func TestCache(t *testing.T) {
+	r, err := NewRouteFromGraph(lineGraph(), dbstore.NewMemory(nil, nil, nil), nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c := NewCache(r, 2)
+	ctx := context.Background()
+
+	first, err := c.Find(ctx, "A", "E")
+	if err != nil || len(first) != 1 {
+		t.Fatalf("ожидался один маршрут: %v %v", first, err)
+	}
+	if _, err := c.Find(ctx, "a", "e"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if st := c.Stats(); st.Hits != 1 || st.Misses != 1 || st.Entries != 1 {
+		t.Fatalf("неожиданная статистика: %+v", st)
+	}
+
+	// одинаковые параметры в разном порядке дают один ключ
+	opts1 := FindOptions{RemovedConnections: []ConnectedSystems{{"B", "A"}, {"C", "D"}}}
+	opts2 := FindOptions{RemovedConnections: []ConnectedSystems{{"d", "c"}, {"a", "b"}, {"A", "B"}}}
+	if opts1.Key() != opts2.Key() {
+		t.Fatalf("ключи отличаются: %q != %q", opts1.Key(), opts2.Key())
+	}
+	if _, err := c.FindWithOptions(ctx, "A", "E", opts1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := c.FindWithOptions(ctx, "A", "E", opts2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if st := c.Stats(); st.Hits != 2 || st.Misses != 2 || st.Entries != 2 {
+		t.Fatalf("неожиданная статистика: %+v", st)
+	}
+
+	// третий ключ вытесняет самый давний (A -> E без параметров)
+	if _, err := c.Find(ctx, "B", "D"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := c.Find(ctx, "A", "E"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if st := c.Stats(); st.Misses != 4 || st.Entries != 2 {
+		t.Fatalf("неожиданная статистика после вытеснения: %+v", st)
+	}
+
+	c.Invalidate()
+	if st := c.Stats(); st.Entries != 0 || st.Version != 1 {
+		t.Fatalf("кэш не сброшен: %+v", st)
+	}
+	if _, err := c.Find(ctx, "A", "E"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if st := c.Stats(); st.Misses != 5 {
+		t.Fatalf("ожидался промах после сброса: %+v", st)
+	}
+}
+
+// TestFindWithOptions проверяет исключение систем и соединений в отдельном поиске.
+func TestFindWithOptions(t *testing.T) {
+	r, err := NewRouteFromGraph(lineGraph(), dbstore.NewMemory(nil, nil, nil), nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx := context.Background()
+	paths, err := r.FindWithOptions(ctx, "A", "E", FindOptions{AvoidedSystems: []int{3}})
+	if err != nil || len(paths) != 0 {
+		t.Fatalf("маршрут через избегаемую систему: %v %v", paths, err)
+	}
+	paths, err = r.FindWithOptions(ctx, "A", "E", FindOptions{RemovedConnections: []ConnectedSystems{{"d", "c"}}})
+	if err != nil || len(paths) != 0 {
+		t.Fatalf("маршрут через удалённое соединение: %v %v", paths, err)
+	}
+	paths, err = r.FindWithOptions(ctx, "A", "E", FindOptions{AvoidedSystems: []int{99}})
+	if err != nil || len(paths) != 1 || len(paths[0]) != 5 {
+		t.Fatalf("ожидался маршрут из 5 систем: %v %v", paths, err)
+	}
+}
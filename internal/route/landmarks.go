@@ -0,0 +1,184 @@
+package route
+
+import (
+	"context"
+	"log"
+)
+
+// landmarks хранит расстояния (в прыжках) от каждого ориентира до всех узлов.
+// Значение -1 означает, что узел недостижим из ориентира.
+type landmarks struct {
+	dist [][]int32
+}
+
+// PrepareLandmarks выбирает count ориентиров и рассчитывает расстояния от них,
+// после чего поиск использует A* с эвристикой ALT вместо двунаправленного поиска.
+// Подготовка занимает count обходов графа и окупается на больших пакетах запросов.
+// Метод можно вызывать параллельно с поиском: ориентиры подменяются атомарно.
+func (r *Route) PrepareLandmarks(count int) {
+	if count <= 0 || len(r.nodes) == 0 {
+		r.landmarks.Store(nil)
+		return
+	}
+	n := len(r.nodes)
+	// minDist — расстояние от узла до ближайшего уже выбранного ориентира.
+	minDist := make([]int32, n)
+	for i := range minDist {
+		minDist[i] = -1
+	}
+	l := &landmarks{}
+	next := r.farthest(r.bfsDistances(0), minDist)
+	for len(l.dist) < count {
+		d := r.bfsDistances(next)
+		l.dist = append(l.dist, d)
+		for i, v := range d {
+			if v >= 0 && (minDist[i] < 0 || v < minDist[i]) {
+				minDist[i] = v
+			}
+		}
+		next = r.farthest(d, minDist)
+	}
+	log.Printf("route planner: prepared %d landmarks for %d nodes", len(l.dist), n)
+	r.landmarks.Store(l)
+}
+
+// farthest выбирает следующий ориентир: узел, ещё не покрытый ни одним ориентиром,
+// или наиболее удалённый от уже выбранных.
+func (r *Route) farthest(d, minDist []int32) int {
+	best, bestDist := 0, int32(-1)
+	for i := range d {
+		if minDist[i] < 0 && d[i] < 0 {
+			return i
+		}
+		if minDist[i] > bestDist {
+			best, bestDist = i, minDist[i]
+		}
+		if minDist[i] < 0 && d[i] > bestDist {
+			best, bestDist = i, d[i]
+		}
+	}
+	return best
+}
+
+// bfsDistances возвращает расстояния от узла с индексом from до всех узлов.
+func (r *Route) bfsDistances(from int) []int32 {
+	d := make([]int32, len(r.nodes))
+	for i := range d {
+		d[i] = -1
+	}
+	d[from] = 0
+	queue := []int{from}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, c := range r.nodes[cur].connections {
+			if d[c.Node.index] < 0 {
+				d[c.Node.index] = d[cur] + 1
+				queue = append(queue, c.Node.index)
+			}
+		}
+	}
+	return d
+}
+
+// estimate возвращает нижнюю оценку числа прыжков между узлами по неравенству треугольника.
+func (l *landmarks) estimate(v, goal int) int32 {
+	var h int32
+	for _, d := range l.dist {
+		if d[v] < 0 || d[goal] < 0 {
+			continue
+		}
+		diff := d[goal] - d[v]
+		if diff < 0 {
+			diff = -diff
+		}
+		if diff > h {
+			h = diff
+		}
+	}
+	return h
+}
+
+// astar ищет кратчайший путь от start до goal алгоритмом A* с эвристикой ALT.
+func (r *Route) astar(ctx context.Context, st *searchState, f *filter, l *landmarks, start, goal *Node, maxDepth int) ([]Connection, error) {
+	st.reset()
+	st.mark(forward, start, 0, Connection{})
+	st.heap = append(st.heap[:0], heapItem{node: start, f: l.estimate(start.index, goal.index)})
+	for expanded := 0; len(st.heap) > 0; expanded++ {
+		if expanded%checkEvery == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		item := st.heap.pop()
+		cur := item.node
+		// seen[backward] отмечает уже раскрытые узлы.
+		if st.visited(backward, cur) {
+			continue
+		}
+		if maxDepth >= 0 && int(item.f) > maxDepth {
+			return nil, nil
+		}
+		if cur == goal {
+			return st.forwardPath(goal, 0), nil
+		}
+		st.seen[backward][cur.index] = st.gen
+		g := st.dist[forward][cur.index] + 1
+		for _, c := range cur.connections {
+			if !f.allows(cur, c.Node) {
+				continue
+			}
+			if st.visited(forward, c.Node) && st.dist[forward][c.Node.index] <= g {
+				continue
+			}
+			st.mark(forward, c.Node, g, Connection{Node: cur, Type: c.Type})
+			st.heap.push(heapItem{node: c.Node, f: g + l.estimate(c.Node.index, goal.index)})
+		}
+	}
+	return nil, nil
+}
+
+type heapItem struct {
+	node *Node
+	f    int32
+}
+
+// nodeHeap — двоичная куча с минимальной оценкой f в корне.
+type nodeHeap []heapItem
+
+func (h *nodeHeap) push(item heapItem) {
+	*h = append(*h, item)
+	q := *h
+	for i := len(q) - 1; i > 0; {
+		parent := (i - 1) / 2
+		if q[parent].f <= q[i].f {
+			break
+		}
+		q[parent], q[i] = q[i], q[parent]
+		i = parent
+	}
+}
+
+func (h *nodeHeap) pop() heapItem {
+	q := *h
+	top := q[0]
+	last := len(q) - 1
+	q[0] = q[last]
+	q = q[:last]
+	for i := 0; ; {
+		smallest := i
+		if l := 2*i + 1; l < len(q) && q[l].f < q[smallest].f {
+			smallest = l
+		}
+		if r := 2*i + 2; r < len(q) && q[r].f < q[smallest].f {
+			smallest = r
+		}
+		if smallest == i {
+			break
+		}
+		q[i], q[smallest] = q[smallest], q[i]
+		i = smallest
+	}
+	*h = q
+	return top
+}
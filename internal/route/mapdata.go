@@ -0,0 +1,71 @@
+package route
+
+import (
+	"sort"
+	"time"
+)
+
+// MapSystem — система на конце соединения для отображения на карте.
+type MapSystem struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	RegionID int    `json:"regionId"`
+	Region   string `json:"region"`
+	// X, Y — положение системы на двумерной карте (graph.System.MapX, MapY).
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// MapConnection — Ansiblex или временное соединение между двумя системами.
+type MapConnection struct {
+	Type    WaypointType `json:"type"`
+	System1 MapSystem    `json:"system1"`
+	System2 MapSystem    `json:"system2"`
+	// Expires — время закрытия временного соединения, если оно известно.
+	Expires *time.Time `json:"expires,omitempty"`
+}
+
+// MapConnections возвращает все соединения маршрутизатора, кроме звёздных врат
+// и уже закрывшихся временных соединений.
+// System1 — система с меньшим ID; список упорядочен по типу и ID систем.
+func (r *Route) MapConnections() []MapConnection {
+	now := time.Now()
+	regions := r.graphHelper.Graph().Regions
+	mapSystem := func(s GraphSystem) MapSystem {
+		return MapSystem{ID: s.ID, Name: s.Name, RegionID: s.RegionID, Region: regions[s.RegionID], X: s.MapX, Y: s.MapY}
+	}
+	res := []MapConnection{}
+	for _, n := range r.nodes {
+		for _, c := range n.connections {
+			// каждое соединение хранится в обоих узлах, берём его один раз
+			if c.Type == TypeStargate || n.Value.ID > c.Node.Value.ID {
+				continue
+			}
+			mc := MapConnection{Type: c.Type, System1: mapSystem(n.Value), System2: mapSystem(c.Node.Value)}
+			if c.Type == TypeTemporary {
+				mc.Expires = r.tempExpires[[2]int{n.Value.ID, c.Node.Value.ID}]
+				if mc.Expires != nil && !mc.Expires.After(now) {
+					continue
+				}
+			}
+			res = append(res, mc)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		a, b := res[i], res[j]
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		if a.System1.ID != b.System1.ID {
+			return a.System1.ID < b.System1.ID
+		}
+		return a.System2.ID < b.System2.ID
+	})
+	return res
+}
+
+// MapConnections возвращает соединения текущего маршрутизатора (см. Route.MapConnections);
+// после Rebuild список отражает новые соединения.
+func (c *Cache) MapConnections() []MapConnection {
+	return c.Route().MapConnections()
+}
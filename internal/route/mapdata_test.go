@@ -0,0 +1,57 @@
+package route
+
+import (
+	"testing"
+	"time"
+
+	dbstore "github.com/tkhamez/eve-route-go/internal/dbstore"
+	"github.com/tkhamez/eve-route-go/internal/graph"
+)
+
+// TestMapConnections проверяет список соединений для карты.
+func TestMapConnections(t *testing.T) {
+	g := graph.DefaultGraph()
+	g.Systems[2].MapX, g.Systems[2].MapY = 10, 20
+	expires := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	expired := time.Now().Add(-time.Hour)
+	store := dbstore.NewMemory(
+		[]dbstore.Ansiblex{
+			{ID: 1, Name: graph.AnsiblexName("Gamma", "Alpha", "JB"), SolarSystemID: 3},
+			{ID: 2, Name: graph.AnsiblexName("Alpha", "Gamma", "JB"), SolarSystemID: 1},
+		},
+		[]dbstore.TemporaryConnection{
+			{System1ID: 3, System2ID: 2, Expires: &expires},
+			// закрывшееся соединение на карте не показывается
+			{System1ID: 1, System2ID: 2, Expires: &expired},
+		},
+		nil,
+	)
+	r, err := NewRouteFromGraph(g, store, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := r.MapConnections()
+	if len(got) != 2 {
+		t.Fatalf("expected 2 connections, got %+v", got)
+	}
+	a, tmp := got[0], got[1]
+	if a.Type != TypeAnsiblex || a.System1.Name != "Alpha" || a.System2.Name != "Gamma" || a.Expires != nil {
+		t.Errorf("unexpected Ansiblex connection: %+v", a)
+	}
+	if a.System2.X != 10 || a.System2.Y != 20 || a.System2.Region != "Demo Region" {
+		t.Errorf("unexpected map system: %+v", a.System2)
+	}
+	if tmp.Type != TypeTemporary || tmp.System1.ID != 2 || tmp.System2.ID != 3 || tmp.Expires == nil || !tmp.Expires.Equal(expires) {
+		t.Errorf("unexpected temporary connection: %+v", tmp)
+	}
+	c := NewCache(r, 1)
+	if got := c.MapConnections(); len(got) != 2 {
+		t.Errorf("cache should return connections of its route, got %+v", got)
+	}
+	if err := c.Rebuild(g, dbstore.NewMemory(nil, nil, nil)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := c.MapConnections(); len(got) != 0 {
+		t.Errorf("cache should return connections of the rebuilt route, got %+v", got)
+	}
+}
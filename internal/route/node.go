@@ -3,6 +3,7 @@ package route
 // Node представляет узел графа.
 type Node struct {
 	Value       GraphSystem
+	index       int
 	connections []Connection
 }
 
@@ -37,6 +38,9 @@ func (n *Node) isConnected(other *Node, t WaypointType) bool {
 // Connections возвращает все соединения узла.
 func (n *Node) Connections() []Connection { return n.connections }
 
+// Index возвращает порядковый номер узла в маршрутизаторе.
+func (n *Node) Index() int { return n.index }
+
 type path struct {
 	waypoints []Waypoint
 }
@@ -0,0 +1,120 @@
+package route
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FindOptions — параметры отдельного поиска, дополняющие настройки маршрутизатора.
+type FindOptions struct {
+	// AvoidedSystems — ID систем, через которые нельзя прокладывать маршрут.
+	AvoidedSystems []int
+	// RemovedConnections — пары систем, соединения между которыми не используются.
+	RemovedConnections []ConnectedSystems
+}
+
+// Normalize возвращает копию параметров с отсортированными списками без повторов.
+// Имена систем в парах приводятся к нижнему регистру и упорядочиваются.
+func (o FindOptions) Normalize() FindOptions {
+	var n FindOptions
+	if len(o.AvoidedSystems) > 0 {
+		n.AvoidedSystems = append([]int(nil), o.AvoidedSystems...)
+		sort.Ints(n.AvoidedSystems)
+		n.AvoidedSystems = compactInts(n.AvoidedSystems)
+	}
+	seen := map[ConnectedSystems]bool{}
+	for _, c := range o.RemovedConnections {
+		a, b := strings.ToLower(c.System1), strings.ToLower(c.System2)
+		if b < a {
+			a, b = b, a
+		}
+		pair := ConnectedSystems{System1: a, System2: b}
+		if !seen[pair] {
+			seen[pair] = true
+			n.RemovedConnections = append(n.RemovedConnections, pair)
+		}
+	}
+	sort.Slice(n.RemovedConnections, func(i, j int) bool {
+		a, b := n.RemovedConnections[i], n.RemovedConnections[j]
+		if a.System1 != b.System1 {
+			return a.System1 < b.System1
+		}
+		return a.System2 < b.System2
+	})
+	return n
+}
+
+// Key возвращает строку, однозначно описывающую нормализованные параметры.
+func (o FindOptions) Key() string {
+	n := o.Normalize()
+	var sb strings.Builder
+	for _, id := range n.AvoidedSystems {
+		sb.WriteString(strconv.Itoa(id))
+		sb.WriteByte(',')
+	}
+	sb.WriteByte('|')
+	for _, c := range n.RemovedConnections {
+		sb.WriteString(c.System1)
+		sb.WriteByte('\x00')
+		sb.WriteString(c.System2)
+		sb.WriteByte('\x00')
+	}
+	return sb.String()
+}
+
+func compactInts(s []int) []int {
+	out := s[:0]
+	for i, v := range s {
+		if i == 0 || v != s[i-1] {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// filter исключает узлы и соединения из отдельного поиска.
+// Нулевой указатель пропускает всё.
+type filter struct {
+	avoided map[*Node]bool
+	removed map[[2]*Node]bool
+}
+
+// newFilter строит фильтр для параметров поиска или возвращает nil, если они пусты.
+func (r *Route) newFilter(o FindOptions) *filter {
+	if len(o.AvoidedSystems) == 0 && len(o.RemovedConnections) == 0 {
+		return nil
+	}
+	f := &filter{avoided: map[*Node]bool{}, removed: map[[2]*Node]bool{}}
+	for _, id := range o.AvoidedSystems {
+		if n := r.allNodes[id]; n != nil {
+			f.avoided[n] = true
+		}
+	}
+	for _, c := range o.RemovedConnections {
+		s1 := r.graphHelper.FindSystemByName(c.System1)
+		s2 := r.graphHelper.FindSystemByName(c.System2)
+		if s1 == nil || s2 == nil {
+			continue
+		}
+		n1, n2 := r.allNodes[s1.ID], r.allNodes[s2.ID]
+		if n1 != nil && n2 != nil {
+			f.removed[[2]*Node{n1, n2}] = true
+			f.removed[[2]*Node{n2, n1}] = true
+		}
+	}
+	return f
+}
+
+// allowsNode сообщает, можно ли использовать узел.
+func (f *filter) allowsNode(n *Node) bool {
+	return f == nil || !f.avoided[n]
+}
+
+// allows сообщает, можно ли перейти из from в to.
+func (f *filter) allows(from, to *Node) bool {
+	if f == nil {
+		return true
+	}
+	return !f.avoided[to] && !f.removed[[2]*Node{from, to}]
+}
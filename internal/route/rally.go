@@ -0,0 +1,163 @@
+package route
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+)
+
+// RallyMode определяет критерий выбора точки сбора флота.
+type RallyMode string
+
+const (
+	// RallyMinMax минимизирует максимальное число прыжков среди участников.
+	RallyMinMax RallyMode = "max"
+	// RallyMinTotal минимизирует суммарное число прыжков всех участников.
+	RallyMinTotal RallyMode = "total"
+)
+
+// RallyRoute — маршрут одного участника до точки сбора.
+type RallyRoute struct {
+	Member    string
+	Jumps     int
+	Waypoints []Waypoint
+}
+
+// RallyCandidate — система-кандидат для точки сбора.
+type RallyCandidate struct {
+	SystemID   int
+	SystemName string
+	RegionName string
+	MaxJumps   int
+	TotalJumps int
+	Routes     []RallyRoute
+}
+
+// bfsTree хранит результат поиска в ширину из одной системы:
+// число прыжков (-1 для недостижимых узлов) и соединение, по которому узел был достигнут.
+// Оба среза индексируются по Node.index.
+type bfsTree struct {
+	dist   []int32
+	parent []Connection
+}
+
+// RallyPoints ищет системы, наиболее удобные для сбора флота из систем members.
+// Для каждого участника выполняется отдельный поиск в ширину по всем соединениям,
+// затем системы, достижимые для всех, сортируются по выбранному критерию.
+// Возвращает не более limit кандидатов вместе с маршрутами участников.
+func (r *Route) RallyPoints(ctx context.Context, members []string, mode RallyMode, limit int) ([]RallyCandidate, error) {
+	log.Printf("route planner: rally point for %d members (%s)", len(members), mode)
+	if len(members) == 0 {
+		return nil, fmt.Errorf("no members")
+	}
+	if mode != RallyMinMax && mode != RallyMinTotal {
+		return nil, fmt.Errorf("unknown rally mode %q", mode)
+	}
+	trees := make([]bfsTree, 0, len(members))
+	for _, m := range members {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		s := r.graphHelper.FindSystemByName(m)
+		if s == nil {
+			return nil, fmt.Errorf("system not found: %s", m)
+		}
+		node := r.allNodes[s.ID]
+		if node == nil {
+			return nil, fmt.Errorf("system has no connections: %s", m)
+		}
+		trees = append(trees, r.bfs(node))
+	}
+
+	var candidates []RallyCandidate
+	for _, node := range r.nodes {
+		c := RallyCandidate{
+			SystemID:   node.Value.ID,
+			SystemName: node.Value.Name,
+			RegionName: r.graphHelper.Graph().Regions[node.Value.RegionID],
+		}
+		reachable := true
+		for _, t := range trees {
+			d := int(t.dist[node.index])
+			if d < 0 {
+				reachable = false
+				break
+			}
+			c.TotalJumps += d
+			if d > c.MaxJumps {
+				c.MaxJumps = d
+			}
+		}
+		if reachable {
+			candidates = append(candidates, c)
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		primaryA, secondaryA := a.MaxJumps, a.TotalJumps
+		primaryB, secondaryB := b.MaxJumps, b.TotalJumps
+		if mode == RallyMinTotal {
+			primaryA, secondaryA = secondaryA, primaryA
+			primaryB, secondaryB = secondaryB, primaryB
+		}
+		if primaryA != primaryB {
+			return primaryA < primaryB
+		}
+		if secondaryA != secondaryB {
+			return secondaryA < secondaryB
+		}
+		return a.SystemName < b.SystemName
+	})
+	if limit > 0 && len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+
+	for i := range candidates {
+		target := r.allNodes[candidates[i].SystemID]
+		for j, t := range trees {
+			p := t.pathTo(target)
+			candidates[i].Routes = append(candidates[i].Routes, RallyRoute{
+				Member:    members[j],
+				Jumps:     len(p) - 1,
+				Waypoints: r.buildWaypoints(p),
+			})
+		}
+	}
+	return candidates, nil
+}
+
+// bfs выполняет поиск в ширину из start по всем соединениям графа.
+func (r *Route) bfs(start *Node) bfsTree {
+	t := bfsTree{
+		dist:   r.bfsDistances(start.index),
+		parent: make([]Connection, len(r.nodes)),
+	}
+	for _, n := range r.nodes {
+		if t.dist[n.index] <= 0 {
+			continue
+		}
+		// родителем становится первый сосед, расположенный на прыжок ближе к start
+		for _, c := range n.connections {
+			if t.dist[c.Node.index] == t.dist[n.index]-1 {
+				t.parent[n.index] = Connection{Node: c.Node, Type: c.Type}
+				break
+			}
+		}
+	}
+	return t
+}
+
+// pathTo восстанавливает путь от корня дерева до target в формате,
+// который ожидает buildWaypoints.
+func (t bfsTree) pathTo(target *Node) []Connection {
+	n := int(t.dist[target.index]) + 1
+	path := make([]Connection, n)
+	for cur, i := target, n-1; i >= 0; i-- {
+		p := t.parent[cur.index]
+		path[i] = Connection{Node: cur, Type: p.Type}
+		cur = p.Node
+	}
+	return path
+}
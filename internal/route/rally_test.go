@@ -0,0 +1,76 @@
+package route
+
+import (
+	"context"
+	"testing"
+
+	dbstore "github.com/tkhamez/eve-route-go/internal/dbstore"
+	"github.com/tkhamez/eve-route-go/internal/graph"
+)
+
+// lineGraph возвращает цепочку систем A-B-C-D-E.
+func lineGraph() graph.Graph {
+	return graph.Graph{
+		Systems: []graph.System{
+			{ID: 1, Name: "A", RegionID: 1},
+			{ID: 2, Name: "B", RegionID: 1},
+			{ID: 3, Name: "C", RegionID: 1},
+			{ID: 4, Name: "D", RegionID: 1},
+			{ID: 5, Name: "E", RegionID: 1},
+		},
+		Connections: [][2]int{{1, 2}, {2, 3}, {3, 4}, {4, 5}},
+		Regions:     map[int]string{1: "Line"},
+	}
+}
+
+// TestRallyPoints проверяет выбор точки сбора по обоим критериям.
+func TestRallyPoints(t *testing.T) {
+	r, err := NewRouteFromGraph(lineGraph(), dbstore.NewMemory(nil, nil, nil), nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	members := []string{"A", "B", "E"}
+
+	byMax, err := r.RallyPoints(context.Background(), members, RallyMinMax, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(byMax) != 2 {
+		t.Fatalf("ожидалось 2 кандидата, получено %d", len(byMax))
+	}
+	if byMax[0].SystemName != "C" || byMax[0].MaxJumps != 2 || byMax[0].TotalJumps != 5 {
+		t.Fatalf("неожиданный кандидат: %+v", byMax[0])
+	}
+	if len(byMax[0].Routes) != 3 {
+		t.Fatalf("ожидалось 3 маршрута, получено %d", len(byMax[0].Routes))
+	}
+	route := byMax[0].Routes[2]
+	if route.Member != "E" || route.Jumps != 2 || len(route.Waypoints) != 3 {
+		t.Fatalf("неожиданный маршрут: %+v", route)
+	}
+	if route.Waypoints[0].SystemName != "E" || route.Waypoints[2].SystemName != "C" {
+		t.Fatalf("неверный порядок точек: %+v", route.Waypoints)
+	}
+
+	byTotal, err := r.RallyPoints(context.Background(), members, RallyMinTotal, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if byTotal[0].SystemName != "B" || byTotal[0].TotalJumps != 4 {
+		t.Fatalf("неожиданный кандидат: %+v", byTotal[0])
+	}
+}
+
+// TestRallyPointsUnknownSystem проверяет ошибку для неизвестной системы.
+func TestRallyPointsUnknownSystem(t *testing.T) {
+	r, err := NewRouteFromGraph(lineGraph(), dbstore.NewMemory(nil, nil, nil), nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := r.RallyPoints(context.Background(), []string{"A", "Unknown"}, RallyMinMax, 5); err == nil {
+		t.Fatalf("ожидалась ошибка для неизвестной системы")
+	}
+	if _, err := r.RallyPoints(context.Background(), []string{"A"}, "median", 5); err == nil {
+		t.Fatalf("ожидалась ошибка для неизвестного режима")
+	}
+}
@@ -4,12 +4,17 @@ import (
 	"context"
 	"log"
 	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	dbstore "github.com/tkhamez/eve-route-go/internal/dbstore"
 	"github.com/tkhamez/eve-route-go/internal/graph"
 )
 
 // Route ищет пути между системами на основании графа.
+// Граф строится в конструкторе и после этого не изменяется, поэтому
+// один экземпляр можно использовать из нескольких горутин одновременно.
 type Route struct {
 	graphHelper        *graph.Helper
 	avoidedSystems     map[int]bool
@@ -19,11 +24,22 @@ type Route struct {
 	allNodes                map[int]*Node
 	allAnsiblexes           map[int]Ansiblex
 	allTemporaryConnections map[int]TemporaryConnection
+	// tempExpires — время закрытия временных соединений по паре ID (меньший первым).
+	tempExpires map[[2]int]*time.Time
+
+	// nodes — все узлы по порядку индексов, используется для поиска по массивам.
+	nodes     []*Node
+	landmarks atomic.Pointer[landmarks]
+	statePool sync.Pool
 }
 
 // NewRoute создаёт новый экземпляр маршрутизатора и загружает данные из хранилища.
-func NewRoute(store dbstore.Store, avoided map[int]bool, removed []ConnectedSystems) (*Route, error) {
-	g := graph.DefaultGraph()
+func NewRoute(store dbstore.ConnectionStore, avoided map[int]bool, removed []ConnectedSystems) (*Route, error) {
+	return NewRouteFromGraph(graph.DefaultGraph(), store, avoided, removed)
+}
+
+// NewRouteFromGraph создаёт маршрутизатор поверх указанного графа карты.
+func NewRouteFromGraph(g graph.Graph, store dbstore.ConnectionStore, avoided map[int]bool, removed []ConnectedSystems) (*Route, error) {
 	helper := graph.NewHelper(g)
 	r := &Route{
 		graphHelper:             helper,
@@ -33,6 +49,7 @@ func NewRoute(store dbstore.Store, avoided map[int]bool, removed []ConnectedSyst
 		allNodes:                map[int]*Node{},
 		allAnsiblexes:           map[int]Ansiblex{},
 		allTemporaryConnections: map[int]TemporaryConnection{},
+		tempExpires:             map[[2]int]*time.Time{},
 	}
 	r.buildNodes()
 	ansiblexes, err := store.Ansiblexes(context.Background())
@@ -49,24 +66,34 @@ func NewRoute(store dbstore.Store, avoided map[int]bool, removed []ConnectedSyst
 }
 
 // Find ищет пути от from до to. Возвращает список маршрутов с набором точек.
-func (r *Route) Find(from, to string) [][]Waypoint {
+// Поиск прерывается с ошибкой контекста, если ctx отменён.
+func (r *Route) Find(ctx context.Context, from, to string) ([][]Waypoint, error) {
+	return r.FindWithOptions(ctx, from, to, FindOptions{})
+}
+
+// FindWithOptions ищет пути от from до to с учётом параметров отдельного поиска.
+func (r *Route) FindWithOptions(ctx context.Context, from, to string, opts FindOptions) ([][]Waypoint, error) {
 	log.Printf("route planner: %s -> %s", from, to)
 	startSystem := r.graphHelper.FindSystemByName(from)
 	endSystem := r.graphHelper.FindSystemByName(to)
 	if startSystem == nil || endSystem == nil {
-		return [][]Waypoint{}
+		return [][]Waypoint{}, nil
 	}
+	f := r.newFilter(opts)
 	startNode := r.allNodes[startSystem.ID]
-	if startNode == nil {
-		return [][]Waypoint{}
+	if startNode == nil || !f.allowsNode(startNode) {
+		return [][]Waypoint{}, nil
+	}
+	connections, err := r.search(ctx, f, *endSystem, startNode)
+	if err != nil {
+		return nil, err
 	}
-	connections := r.search(*endSystem, startNode)
-	var paths []path
+	paths := make([]path, 0, len(connections))
 	for _, c := range connections {
 		wp := r.buildWaypoints(c)
 		paths = append(paths, path{waypoints: wp})
 	}
-	sort.Slice(paths, func(i, j int) bool {
+	sort.SliceStable(paths, func(i, j int) bool {
 		ai := paths[i].numberOfAnsiblexes()
 		aj := paths[j].numberOfAnsiblexes()
 		if ai == aj {
@@ -78,9 +105,26 @@ func (r *Route) Find(from, to string) [][]Waypoint {
 	for _, p := range paths {
 		result = append(result, p.waypoints)
 	}
-	return result
+	return result, nil
+}
+
+// Source возвращает текущий маршрутизатор; реализуется Route и Cache.
+// Обработчики, которым нужен граф целиком, получают маршрутизатор на каждый запрос,
+// чтобы видеть изменения Ansiblex и временных соединений.
+type Source interface {
+	Route() *Route
 }
 
+// Route возвращает сам маршрутизатор (см. Source).
+func (r *Route) Route() *Route { return r }
+
+// Nodes возвращает все узлы в порядке их индексов (см. Node.Index).
+// Срез и узлы общие для всех вызывающих и не должны изменяться.
+func (r *Route) Nodes() []*Node { return r.nodes }
+
+// Graph возвращает граф карты, поверх которого построен маршрутизатор.
+func (r *Route) Graph() graph.Graph { return r.graphHelper.Graph() }
+
 // buildNodes создаёт узлы и соединяет их в соответствии с графом.
 func (r *Route) buildNodes() {
 	g := r.graphHelper.Graph()
@@ -112,9 +156,15 @@ func (r *Route) addGates(ansiblexes []Ansiblex) {
 }
 
 func (r *Route) addTempConnections(conns []TemporaryConnection) {
+	now := time.Now()
 	for _, c := range conns {
+		// закрывшиеся соединения не используются
+		if c.Expires != nil && !c.Expires.After(now) {
+			continue
+		}
 		r.allTemporaryConnections[c.System1ID] = c
 		r.allTemporaryConnections[c.System2ID] = c
+		r.tempExpires[[2]int{min(c.System1ID, c.System2ID), max(c.System1ID, c.System2ID)}] = c.Expires
 		n1 := r.getNode(c.System1ID)
 		n2 := r.getNode(c.System2ID)
 		if n1 != nil && n2 != nil && !r.isRemoved(n1.Value.Name, n2.Value.Name) {
@@ -140,52 +190,14 @@ func (r *Route) getNode(systemID int) *Node {
 		return n
 	}
 	if s, ok := r.allSystems[systemID]; ok {
-		node := &Node{Value: s}
+		node := &Node{Value: s, index: len(r.nodes)}
 		r.allNodes[systemID] = node
+		r.nodes = append(r.nodes, node)
 		return node
 	}
 	return nil
 }
 
-func (r *Route) search(goal GraphSystem, start *Node) [][]Connection {
-	type connPath []Connection
-	var found []connPath
-	queue := []connPath{{{Node: start}}}
-	visited := map[*Node]bool{}
-	lastLen := 0
-	for len(queue) > 0 {
-		p := queue[0]
-		queue = queue[1:]
-		current := p[len(p)-1]
-		if lastLen > 0 && len(p) > lastLen {
-			continue
-		}
-		if current.Node.Value.ID == goal.ID {
-			if lastLen == 0 || len(p) < lastLen {
-				found = []connPath{p}
-				lastLen = len(p)
-			} else if len(p) == lastLen {
-				found = append(found, p)
-			}
-			continue
-		}
-		if visited[current.Node] {
-			continue
-		}
-		visited[current.Node] = true
-		for _, c := range current.Node.Connections() {
-			newPath := append(connPath{}, p...)
-			newPath = append(newPath, c)
-			queue = append(queue, newPath)
-		}
-	}
-	var result [][]Connection
-	for _, p := range found {
-		result = append(result, []Connection(p))
-	}
-	return result
-}
-
 func (r *Route) buildWaypoints(path []Connection) []Waypoint {
 	var waypoints []Waypoint
 	for i := len(path) - 1; i >= 0; i-- {
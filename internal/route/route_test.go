@@ -1,7 +1,9 @@
 package route
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	dbstore "github.com/tkhamez/eve-route-go/internal/dbstore"
 )
@@ -17,7 +19,10 @@ func TestRouteFindAnsiblex(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	paths := r.Find("Alpha", "Gamma")
+	paths, err := r.Find(context.Background(), "Alpha", "Gamma")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 	t.Logf("paths: %d", len(paths))
 	if len(paths) != 2 {
 		t.Fatalf("ожидались два маршрута, получено %d", len(paths))
@@ -40,7 +45,10 @@ func TestRouteSortTemporary(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	paths := r.Find("Alpha", "Gamma")
+	paths, err := r.Find(context.Background(), "Alpha", "Gamma")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 	if len(paths) != 2 {
 		t.Fatalf("ожидались два маршрута, получено %d", len(paths))
 	}
@@ -51,3 +59,23 @@ func TestRouteSortTemporary(t *testing.T) {
 		t.Fatalf("второй маршрут должен использовать временное соединение")
 	}
 }
+
+// TestRouteExpiredTemporary проверяет, что закрывшееся временное соединение не используется.
+func TestRouteExpiredTemporary(t *testing.T) {
+	expired := time.Now().Add(-time.Minute)
+	temps := []dbstore.TemporaryConnection{
+		{System1ID: 1, System2ID: 3, Expires: &expired},
+	}
+	store := dbstore.NewMemory(nil, temps, nil)
+	r, err := NewRoute(store, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	paths, err := r.Find(context.Background(), "Alpha", "Gamma")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(paths) != 1 || paths[0][0].ConnectionType == nil || *paths[0][0].ConnectionType != TypeStargate {
+		t.Fatalf("ожидался один маршрут через врата, получено %+v", paths)
+	}
+}
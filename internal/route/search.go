@@ -0,0 +1,219 @@
+package route
+
+import "context"
+
+// checkEvery — как часто (в раскрытых узлах) A* проверяет отмену контекста.
+const checkEvery = 256
+
+const (
+	forward  = 0
+	backward = 1
+)
+
+// searchState содержит переиспользуемые буферы поиска, индексированные по Node.index.
+// Отметки поколений позволяют не очищать массивы между поисками.
+type searchState struct {
+	gen      uint32
+	seen     [2][]uint32
+	dist     [2][]int32
+	parent   [2][]Connection
+	frontier [2][]*Node
+	next     []*Node
+	heap     nodeHeap
+}
+
+// acquireState берёт буферы поиска из пула и подготавливает их к новому поиску.
+func (r *Route) acquireState() *searchState {
+	st, _ := r.statePool.Get().(*searchState)
+	if st == nil {
+		st = &searchState{}
+	}
+	n := len(r.nodes)
+	if len(st.seen[forward]) < n {
+		for side := range st.seen {
+			st.seen[side] = make([]uint32, n)
+			st.dist[side] = make([]int32, n)
+			st.parent[side] = make([]Connection, n)
+		}
+		st.gen = 0
+	}
+	return st
+}
+
+// releaseState возвращает буферы в пул.
+func (r *Route) releaseState(st *searchState) {
+	r.statePool.Put(st)
+}
+
+// reset начинает новое поколение отметок.
+func (st *searchState) reset() {
+	st.gen++
+	if st.gen == 0 {
+		for side := range st.seen {
+			clear(st.seen[side])
+		}
+		st.gen = 1
+	}
+}
+
+func (st *searchState) visited(side int, n *Node) bool {
+	return st.seen[side][n.index] == st.gen
+}
+
+func (st *searchState) mark(side int, n *Node, d int32, parent Connection) {
+	st.seen[side][n.index] = st.gen
+	st.dist[side][n.index] = d
+	st.parent[side][n.index] = parent
+}
+
+// search ищет кратчайшие пути от start до goal. Для каждого соединения, ведущего
+// в goal из системы на кратчайшем расстоянии, возвращается один путь.
+// Первый элемент пути содержит только стартовый узел, каждый следующий —
+// узел и тип соединения, по которому в него пришли.
+func (r *Route) search(ctx context.Context, f *filter, goal GraphSystem, start *Node) ([][]Connection, error) {
+	goalNode := r.allNodes[goal.ID]
+	if goalNode == nil || !f.allowsNode(goalNode) {
+		return nil, nil
+	}
+	if goalNode == start {
+		return [][]Connection{{{Node: start}}}, nil
+	}
+	st := r.acquireState()
+	defer r.releaseState(st)
+
+	best, err := r.shortest(ctx, st, f, start, goalNode, -1)
+	if best == nil {
+		return nil, err
+	}
+	jumps := len(best) - 1
+
+	var result [][]Connection
+	prefixes := map[*Node][]Connection{}
+	for _, c := range goalNode.connections {
+		if !f.allows(c.Node, goalNode) {
+			continue
+		}
+		prefix, ok := prefixes[c.Node]
+		if !ok {
+			prefix, err = r.shortest(ctx, st, f, start, c.Node, jumps-1)
+			if err != nil {
+				return nil, err
+			}
+			if len(prefix)-1 != jumps-1 {
+				prefix = nil
+			}
+			prefixes[c.Node] = prefix
+		}
+		if prefix == nil {
+			continue
+		}
+		p := make([]Connection, len(prefix), len(prefix)+1)
+		copy(p, prefix)
+		result = append(result, append(p, Connection{Node: goalNode, Type: c.Type}))
+	}
+	return result, nil
+}
+
+// shortest возвращает один кратчайший путь от start до goal не длиннее maxDepth
+// прыжков (без ограничения, если maxDepth < 0). Если подготовлены ориентиры,
+// используется A* с эвристикой ALT, иначе двунаправленный поиск в ширину.
+func (r *Route) shortest(ctx context.Context, st *searchState, f *filter, start, goal *Node, maxDepth int) ([]Connection, error) {
+	if start == goal {
+		return []Connection{{Node: start}}, nil
+	}
+	if l := r.landmarks.Load(); l != nil {
+		return r.astar(ctx, st, f, l, start, goal, maxDepth)
+	}
+	return r.bidirectional(ctx, st, f, start, goal, maxDepth)
+}
+
+// bidirectional выполняет поиск в ширину одновременно от start и от goal,
+// каждый раз раскрывая меньший фронт целым уровнем.
+func (r *Route) bidirectional(ctx context.Context, st *searchState, f *filter, start, goal *Node, maxDepth int) ([]Connection, error) {
+	st.reset()
+	st.mark(forward, start, 0, Connection{})
+	st.mark(backward, goal, 0, Connection{})
+	st.frontier[forward] = append(st.frontier[forward][:0], start)
+	st.frontier[backward] = append(st.frontier[backward][:0], goal)
+	var depth [2]int32
+
+	for len(st.frontier[forward]) > 0 && len(st.frontier[backward]) > 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if maxDepth >= 0 && int(depth[forward]+depth[backward])+1 > maxDepth {
+			return nil, nil
+		}
+		side := forward
+		if len(st.frontier[backward]) < len(st.frontier[forward]) {
+			side = backward
+		}
+		other := 1 - side
+
+		best := int32(-1)
+		var meetNear, meetFar *Node
+		var meetType WaypointType
+		next := st.next[:0]
+		for _, cur := range st.frontier[side] {
+			for _, c := range cur.connections {
+				if !f.allows(cur, c.Node) {
+					continue
+				}
+				if st.visited(other, c.Node) {
+					total := depth[side] + 1 + st.dist[other][c.Node.index]
+					if best < 0 || total < best {
+						best, meetNear, meetFar, meetType = total, cur, c.Node, c.Type
+					}
+				}
+				if st.visited(side, c.Node) {
+					continue
+				}
+				st.mark(side, c.Node, depth[side]+1, Connection{Node: cur, Type: c.Type})
+				next = append(next, c.Node)
+			}
+		}
+		depth[side]++
+		st.next = st.frontier[side]
+		st.frontier[side] = next
+
+		if best >= 0 {
+			if maxDepth >= 0 && int(best) > maxDepth {
+				return nil, nil
+			}
+			if side == forward {
+				return st.join(meetNear, meetFar, meetType), nil
+			}
+			return st.join(meetFar, meetNear, meetType), nil
+		}
+	}
+	return nil, nil
+}
+
+// join собирает путь из цепочки прямого поиска до a, соединения a-b
+// и цепочки обратного поиска от b до цели.
+func (st *searchState) join(a, b *Node, t WaypointType) []Connection {
+	path := st.forwardPath(a, int(st.dist[backward][b.index])+1)
+	path = append(path, Connection{Node: b, Type: t})
+	for cur := b; ; {
+		p := st.parent[backward][cur.index]
+		if p.Node == nil {
+			break
+		}
+		path = append(path, Connection{Node: p.Node, Type: p.Type})
+		cur = p.Node
+	}
+	return path
+}
+
+// forwardPath восстанавливает путь от начала прямого поиска до a,
+// оставляя в срезе место ещё для extra элементов.
+func (st *searchState) forwardPath(a *Node, extra int) []Connection {
+	n := int(st.dist[forward][a.index]) + 1
+	path := make([]Connection, n, n+extra)
+	for cur, i := a, n-1; i >= 0; i-- {
+		p := st.parent[forward][cur.index]
+		path[i] = Connection{Node: cur, Type: p.Type}
+		cur = p.Node
+	}
+	return path
+}
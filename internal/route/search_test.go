@@ -0,0 +1,301 @@
+package route
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"sort"
+	"sync"
+	"testing"
+
+	dbstore "github.com/tkhamez/eve-route-go/internal/dbstore"
+	"github.com/tkhamez/eve-route-go/internal/graph"
+)
+
+// universeGraph строит синтетическую карту размером с k-space EVE: регионы по 100 систем,
+// связанные внутри себя и с соседними регионами. Если задана переменная EVE_ROUTE_GRAPH,
+// вместо неё загружается настоящий граф из JSON-файла.
+func universeGraph(tb testing.TB, systems int) graph.Graph {
+	tb.Helper()
+	if path := os.Getenv("EVE_ROUTE_GRAPH"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			tb.Fatalf("read graph: %v", err)
+		}
+		var g graph.Graph
+		if err := json.Unmarshal(data, &g); err != nil {
+			tb.Fatalf("unmarshal graph: %v", err)
+		}
+		return g
+	}
+	rnd := rand.New(rand.NewSource(42))
+	g := graph.Graph{Regions: map[int]string{}}
+	const perRegion = 100
+	for i := 0; i < systems; i++ {
+		region := i / perRegion
+		g.Regions[region] = fmt.Sprintf("Region %d", region)
+		g.Systems = append(g.Systems, graph.System{ID: 30000000 + i, Name: fmt.Sprintf("S%d", i), RegionID: region})
+		if i%perRegion > 0 {
+			// дерево внутри региона плюс случайные дополнительные врата
+			g.Connections = append(g.Connections, [2]int{30000000 + i, 30000000 + i - 1 - rnd.Intn(min(i%perRegion, 5))})
+			if rnd.Intn(4) == 0 {
+				g.Connections = append(g.Connections, [2]int{30000000 + i, 30000000 + region*perRegion + rnd.Intn(i%perRegion)})
+			}
+		}
+		if i%perRegion == 0 && i > 0 {
+			g.Connections = append(g.Connections, [2]int{30000000 + i, 30000000 + rnd.Intn(i)})
+		}
+	}
+	return g
+}
+
+// universeAnsiblexes добавляет к графу count случайных пар Ansiblex.
+func universeAnsiblexes(g graph.Graph, count int) []dbstore.Ansiblex {
+	rnd := rand.New(rand.NewSource(7))
+	var res []dbstore.Ansiblex
+	for i := 0; i < count; i++ {
+		a := g.Systems[rnd.Intn(len(g.Systems))]
+		b := g.Systems[rnd.Intn(len(g.Systems))]
+		res = append(res,
+			dbstore.Ansiblex{ID: int64(2 * i), Name: a.Name + " » " + b.Name + " - JB", SolarSystemID: a.ID},
+			dbstore.Ansiblex{ID: int64(2*i + 1), Name: b.Name + " » " + a.Name + " - JB", SolarSystemID: b.ID},
+		)
+	}
+	return res
+}
+
+// legacySearch — прежний поиск в ширину с копированием путей, используется как эталон.
+func legacySearch(goal GraphSystem, start *Node) [][]Connection {
+	type connPath []Connection
+	var found []connPath
+	queue := []connPath{{{Node: start}}}
+	visited := map[*Node]bool{}
+	lastLen := 0
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		current := p[len(p)-1]
+		if lastLen > 0 && len(p) > lastLen {
+			continue
+		}
+		if current.Node.Value.ID == goal.ID {
+			if lastLen == 0 || len(p) < lastLen {
+				found = []connPath{p}
+				lastLen = len(p)
+			} else if len(p) == lastLen {
+				found = append(found, p)
+			}
+			continue
+		}
+		if visited[current.Node] {
+			continue
+		}
+		visited[current.Node] = true
+		for _, c := range current.Node.Connections() {
+			newPath := append(connPath{}, p...)
+			newPath = append(newPath, c)
+			queue = append(queue, newPath)
+		}
+	}
+	var result [][]Connection
+	for _, p := range found {
+		result = append(result, []Connection(p))
+	}
+	return result
+}
+
+// lastHops описывает набор найденных путей последним прыжком и длиной.
+func lastHops(paths [][]Connection) []string {
+	var res []string
+	for _, p := range paths {
+		last := p[len(p)-1]
+		prev := p[0]
+		if len(p) > 1 {
+			prev = p[len(p)-2]
+		}
+		res = append(res, fmt.Sprintf("%d:%d-%s", len(p), prev.Node.Value.ID, last.Type))
+	}
+	sort.Strings(res)
+	return res
+}
+
+// checkPath проверяет, что путь непрерывен и каждый прыжок существует в графе.
+func checkPath(t *testing.T, p []Connection) {
+	t.Helper()
+	for i := 1; i < len(p); i++ {
+		if !p[i-1].Node.isConnected(p[i].Node, p[i].Type) {
+			t.Fatalf("разрыв пути между %s и %s", p[i-1].Node.Value.Name, p[i].Node.Value.Name)
+		}
+	}
+}
+
+// TestSearchMatchesLegacy сравнивает новый поиск с прежним на синтетической карте.
+func TestSearchMatchesLegacy(t *testing.T) {
+	g := universeGraph(t, 1000)
+	store := dbstore.NewMemory(universeAnsiblexes(g, 40), nil, nil)
+	r, err := NewRouteFromGraph(g, store, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rnd := rand.New(rand.NewSource(1))
+	for _, useLandmarks := range []bool{false, true} {
+		if useLandmarks {
+			r.PrepareLandmarks(8)
+		}
+		for i := 0; i < 100; i++ {
+			start := r.nodes[rnd.Intn(len(r.nodes))]
+			goal := r.nodes[rnd.Intn(len(r.nodes))].Value
+			want := lastHops(legacySearch(goal, start))
+			paths, err := r.search(context.Background(), nil, goal, start)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got := lastHops(paths)
+			if fmt.Sprint(want) != fmt.Sprint(got) {
+				t.Fatalf("landmarks=%v %s -> %s: ожидалось %v, получено %v",
+					useLandmarks, start.Value.Name, goal.Name, want, got)
+			}
+			for _, p := range paths {
+				checkPath(t, p)
+				if p[0].Node != start || p[len(p)-1].Node.Value.ID != goal.ID {
+					t.Fatalf("путь начинается или заканчивается не там")
+				}
+			}
+		}
+	}
+}
+
+// TestFindConcurrent выполняет поиск из нескольких горутин одновременно с подготовкой
+// ориентиров. Предназначен для запуска с флагом -race.
+func TestFindConcurrent(t *testing.T) {
+	g := universeGraph(t, 1000)
+	store := dbstore.NewMemory(universeAnsiblexes(g, 40), nil, nil)
+	r, err := NewRouteFromGraph(g, store, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want, err := r.Find(context.Background(), "S1", "S999")
+	if err != nil || len(want) == 0 {
+		t.Fatalf("ожидался маршрут: %v", err)
+	}
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				got, err := r.Find(context.Background(), "S1", "S999")
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+					return
+				}
+				if len(got) != len(want) {
+					t.Errorf("результат отличается: %d маршрутов вместо %d", len(got), len(want))
+					return
+				}
+				if len(got[0]) != len(want[0]) {
+					t.Errorf("результат отличается: %d систем вместо %d", len(got[0]), len(want[0]))
+					return
+				}
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		r.PrepareLandmarks(4)
+	}()
+	wg.Wait()
+}
+
+// TestFindCancelled проверяет, что отменённый контекст прерывает поиск.
+func TestFindCancelled(t *testing.T) {
+	g := universeGraph(t, 1000)
+	r, err := NewRouteFromGraph(g, dbstore.NewMemory(nil, nil, nil), nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, landmarks := range []int{0, 4} {
+		r.PrepareLandmarks(landmarks)
+		if _, err := r.Find(ctx, "S1", "S999"); err != context.Canceled {
+			t.Fatalf("landmarks=%d: ожидалась context.Canceled, получено %v", landmarks, err)
+		}
+	}
+}
+
+func benchmarkFind(b *testing.B, landmarks int) {
+	g := universeGraph(b, 8000)
+	store := dbstore.NewMemory(universeAnsiblexes(g, 300), nil, nil)
+	r, err := NewRouteFromGraph(g, store, nil, nil)
+	if err != nil {
+		b.Fatalf("unexpected error: %v", err)
+	}
+	r.PrepareLandmarks(landmarks)
+	rnd := rand.New(rand.NewSource(3))
+	pairs := make([][2]string, 256)
+	for i := range pairs {
+		pairs[i] = [2]string{
+			g.Systems[rnd.Intn(len(g.Systems))].Name,
+			g.Systems[rnd.Intn(len(g.Systems))].Name,
+		}
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p := pairs[i%len(pairs)]
+		if _, err := r.Find(context.Background(), p[0], p[1]); err != nil {
+			b.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+
+// BenchmarkFindBidirectional измеряет поиск по полной карте без ориентиров.
+func BenchmarkFindBidirectional(b *testing.B) { benchmarkFind(b, 0) }
+
+// BenchmarkFindLandmarks измеряет поиск по полной карте с 16 ориентирами ALT.
+func BenchmarkFindLandmarks(b *testing.B) { benchmarkFind(b, 16) }
+
+// BenchmarkFindParallel измеряет поиск по полной карте из нескольких горутин.
+func BenchmarkFindParallel(b *testing.B) {
+	g := universeGraph(b, 8000)
+	store := dbstore.NewMemory(universeAnsiblexes(g, 300), nil, nil)
+	r, err := NewRouteFromGraph(g, store, nil, nil)
+	if err != nil {
+		b.Fatalf("unexpected error: %v", err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		rnd := rand.New(rand.NewSource(rand.Int63()))
+		for pb.Next() {
+			from := g.Systems[rnd.Intn(len(g.Systems))].Name
+			to := g.Systems[rnd.Intn(len(g.Systems))].Name
+			if _, err := r.Find(context.Background(), from, to); err != nil {
+				b.Errorf("unexpected error: %v", err)
+				return
+			}
+		}
+	})
+}
+
+// BenchmarkLegacySearch измеряет прежний поиск для сравнения.
+func BenchmarkLegacySearch(b *testing.B) {
+	g := universeGraph(b, 8000)
+	store := dbstore.NewMemory(universeAnsiblexes(g, 300), nil, nil)
+	r, err := NewRouteFromGraph(g, store, nil, nil)
+	if err != nil {
+		b.Fatalf("unexpected error: %v", err)
+	}
+	rnd := rand.New(rand.NewSource(3))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		start := r.nodes[rnd.Intn(len(r.nodes))]
+		goal := r.nodes[rnd.Intn(len(r.nodes))].Value
+		legacySearch(goal, start)
+	}
+}
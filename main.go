@@ -4,7 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"embed"
-	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"net/url"
@@ -17,11 +17,14 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"golang.org/x/oauth2"
 
+	"github.com/tkhamez/eve-route-go/internal/analysis"
 	"github.com/tkhamez/eve-route-go/internal/api"
 	"github.com/tkhamez/eve-route-go/internal/auth"
 	"github.com/tkhamez/eve-route-go/internal/capital"
 	"github.com/tkhamez/eve-route-go/internal/config"
 	"github.com/tkhamez/eve-route-go/internal/db"
+	"github.com/tkhamez/eve-route-go/internal/graph"
+	"github.com/tkhamez/eve-route-go/internal/jumps"
 	routepkg "github.com/tkhamez/eve-route-go/internal/route"
 )
 
@@ -37,30 +40,60 @@ func mustEnv(key string) string {
 	return val
 }
 
+// loadGraph загружает граф карты из GRAPH_FILE или возвращает встроенный граф.
+// Граф проверяется на целостность; в строгом режиме ошибки останавливают сервер.
+func loadGraph(ctx context.Context, path string, strict bool) graph.Graph {
+	g := graph.DefaultGraph()
+	if path != "" {
+		var err error
+		if g, err = db.LoadGraph(ctx, path); err != nil {
+			log.Fatalf("cannot load graph: %v", err)
+		}
+	}
+	if _, err := graph.Check(g, strict, "server"); err != nil {
+		log.Fatalf("cannot use graph: %v", err)
+	}
+	return g
+}
+
+// capitalSystems возвращает системы для планировщика капитальных прыжков:
+// координаты из графа карты, если они есть, иначе встроенный набор.
+func capitalSystems(g graph.Graph) map[int]capital.System {
+	if systems := capital.SystemsFromGraph(g); len(systems) > 0 {
+		return systems
+	}
+	return capital.DefaultSystems()
+}
+
 // initStore инициализирует хранилище данных на основе DATABASE_URL.
-func initStore(ctx context.Context, urlStr string) db.Store {
+// systems используются для хранилища в памяти.
+func initStore(ctx context.Context, urlStr string, systems map[int]capital.System) db.Store {
 	if urlStr == "" {
 		log.Println("DATABASE_URL not set, using in-memory store")
-		return db.NewMemory(nil, nil, capital.DefaultSystems())
+		return db.NewMemory(nil, nil, systems)
 	}
 	u, err := url.Parse(urlStr)
 	if err != nil {
 		log.Printf("invalid DATABASE_URL %q: %v; using in-memory store", urlStr, err)
-		return db.NewMemory(nil, nil, capital.DefaultSystems())
+		return db.NewMemory(nil, nil, systems)
 	}
 	switch u.Scheme {
 	case "postgres", "postgresql":
 		conn, err := sql.Open("postgres", urlStr)
 		if err != nil {
 			log.Printf("postgres connection error: %v; using in-memory store", err)
-			return db.NewMemory(nil, nil, capital.DefaultSystems())
+			return db.NewMemory(nil, nil, systems)
+		}
+		store, err := db.NewPostgres(conn)
+		if err != nil {
+			log.Fatalf("cannot initialise postgres store: %v", err)
 		}
-		return db.NewPostgres(conn)
+		return store
 	case "mongodb", "mongo":
 		client, err := mongo.Connect(ctx, options.Client().ApplyURI(urlStr))
 		if err != nil {
 			log.Printf("mongo connection error: %v; using in-memory store", err)
-			return db.NewMemory(nil, nil, capital.DefaultSystems())
+			return db.NewMemory(nil, nil, systems)
 		}
 		dbName := strings.TrimPrefix(u.Path, "/")
 		if dbName == "" {
@@ -71,17 +104,21 @@ func initStore(ctx context.Context, urlStr string) db.Store {
 		path := strings.TrimPrefix(u.Path, "/")
 		if path == "" {
 			log.Println("sqlite DATABASE_URL missing path, using in-memory store")
-			return db.NewMemory(nil, nil, capital.DefaultSystems())
+			return db.NewMemory(nil, nil, systems)
 		}
 		conn, err := sql.Open("sqlite", path)
 		if err != nil {
 			log.Printf("sqlite connection error: %v; using in-memory store", err)
-			return db.NewMemory(nil, nil, capital.DefaultSystems())
+			return db.NewMemory(nil, nil, systems)
 		}
-		return db.NewSQLite(conn)
+		store, err := db.NewSQLite(conn)
+		if err != nil {
+			log.Fatalf("cannot initialise sqlite store: %v", err)
+		}
+		return store
 	default:
 		log.Printf("unsupported DATABASE_URL scheme %q, using in-memory store", u.Scheme)
-		return db.NewMemory(nil, nil, capital.DefaultSystems())
+		return db.NewMemory(nil, nil, systems)
 	}
 }
 
@@ -89,7 +126,8 @@ func main() {
 	ctx := context.Background()
 	cfg := config.FromEnv()
 
-	store := initStore(ctx, cfg.DatabaseURL)
+	mapGraph := loadGraph(ctx, cfg.GraphFile, cfg.GraphStrict)
+	store := initStore(ctx, cfg.DatabaseURL, capitalSystems(mapGraph))
 
 	tokenDB, err := sql.Open("sqlite", "tokens.db")
 	if err != nil {
@@ -110,43 +148,62 @@ func main() {
 			TokenURL: "https://login.eveonline.com/v2/oauth/token",
 		},
 	}
+	mustEnv("SESSION_KEY")
+	sessions := auth.NewManager()
 	h := auth.NewHandler(oauthConf, tokenStore)
+	h.Sessions = sessions
 
 	r := mux.NewRouter()
 	r.HandleFunc("/login", h.Login).Methods("GET")
 	r.HandleFunc("/callback", h.Callback).Methods("GET")
 
-	api.RegisterAnsiblexRoutes(r, mustEnv("API_SECRET"))
-
-	mustEnv("SESSION_KEY")
-	auth.NewManager()
+	connStore := api.RegisterAnsiblexRoutes(r, mustEnv("API_SECRET"))
 
 	planner, err := capital.NewPlanner(store, 5)
 	if err != nil {
 		log.Fatalf("cannot create planner: %v", err)
 	}
-	r.HandleFunc("/api/capital", func(w http.ResponseWriter, r *http.Request) {
-		start := r.URL.Query().Get("start")
-		end := r.URL.Query().Get("end")
-		if start == "" || end == "" {
-			log.Printf("capital api: missing start or end (start=%q end=%q)", start, end)
-			http.Error(w, "missing start or end", http.StatusBadRequest)
-			return
-		}
-		path, err := planner.Plan(start, end)
-		if err != nil {
-			log.Printf("capital api: %v", err)
-			http.Error(w, err.Error(), http.StatusBadRequest)
-			return
-		}
-		_ = json.NewEncoder(w).Encode(map[string]any{"route": path})
-	}).Methods("GET")
-
-	rp, err := routepkg.NewRoute(store, nil, nil)
+	r.HandleFunc("/api/capital", api.NewCapitalHandler(planner)).Methods("GET")
+	r.HandleFunc("/api/capital/ships", api.NewShipsHandler()).Methods("GET")
+	r.HandleFunc("/api/capital/bridge", api.NewBridgeHandler(planner)).Methods("GET")
+	r.HandleFunc("/api/capital/range", api.NewRangeHandler(planner)).Methods("GET")
+
+	// Маршрутизатор учитывает соединения из базы и из API; после изменений через API
+	// он перестраивается, и все обработчики получают его через routeCache.
+	connections := connStore.Connections(store, mapGraph)
+	rp, err := routepkg.NewRouteFromGraph(mapGraph, connections, nil, nil)
 	if err != nil {
 		log.Fatalf("cannot create route planner: %v", err)
 	}
-	r.HandleFunc("/api/route/{from}/{to}", api.NewRouteHandler(rp)).Methods("GET")
+	routeCache := routepkg.NewCache(rp, cfg.RouteCacheSize)
+	// Отчёты об узких местах считаются в фоне при запуске и после каждого перестроения.
+	reports := analysis.NewReports(routeCache)
+	refreshReports := func() {
+		if err := reports.Refresh(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			log.Printf("cannot analyse chokepoints: %v", err)
+		}
+	}
+	go refreshReports()
+	connStore.OnChange(func() {
+		if err := routeCache.Rebuild(mapGraph, connections); err != nil {
+			log.Printf("cannot rebuild route planner: %v", err)
+			return
+		}
+		go refreshReports()
+	})
+	r.HandleFunc("/api/route/cache-stats", api.NewCacheStatsHandler(routeCache)).Methods("GET")
+	r.HandleFunc("/api/route/map", api.NewMapHandler(routeCache)).Methods("GET")
+	r.HandleFunc("/api/route/map-connections", api.NewMapConnectionsHandler(routeCache)).Methods("GET")
+	// TimeoutHandler отменяет контекст запроса, что прерывает поиск маршрута.
+	r.Handle("/api/capital/hybrid", http.TimeoutHandler(api.NewHybridHandler(capital.NewHybrid(routeCache, planner)), cfg.RouteTimeout, "route search timed out")).Methods("GET")
+	r.HandleFunc("/api/jumps", api.NewJumpsHandler(jumps.NewNetwork(routeCache))).Methods("GET")
+	r.Handle("/api/route/rally", http.TimeoutHandler(api.NewRallyHandler(routeCache), cfg.RouteTimeout, "route search timed out")).Methods("GET")
+	r.HandleFunc("/api/analysis/chokepoints", api.NewChokepointHandler(reports)).Methods("GET")
+	r.Handle("/api/analysis/ansiblex-placement", http.TimeoutHandler(api.NewPlacementHandler(routeCache), cfg.RouteTimeout, "placement timed out")).Methods("POST")
+	r.Handle("/api/whatif", http.TimeoutHandler(api.NewWhatIfHandler(routeCache), cfg.RouteTimeout, "simulation timed out")).Methods("POST")
+	userRoute := api.NewUserRoute(sessions, store, mapGraph)
+	userRoute.Register(r)
+	r.Handle("/api/route/{from}/{to}", http.TimeoutHandler(api.NewRouteHandler(routeCache, userRoute), cfg.RouteTimeout, "route search timed out")).Methods("GET")
 
 	r.PathPrefix("/").Handler(http.FileServer(http.FS(frontendFS)))
 